@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/flmailla/resume/db"
+	"github.com/flmailla/resume/logger"
+)
+
+// Name of the scheduled backups, sorting as their dates
+const backupLayout string = "resume-20060102T150405Z.db"
+
+// Pool the backups read from, sparing the write connection
+func backupSource() *sql.DB {
+	if db.ReadDB != nil {
+		return db.ReadDB
+	}
+	return db.DB
+}
+
+// Back the database up into dir every interval, keeping the most recent
+// backups only. The returned function stops the schedule, waiting for
+// a running backup to complete.
+func scheduleBackups(src *sql.DB, dir string, interval time.Duration, keep int) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				start := time.Now()
+				path, err := backupInto(src, dir, keep, now)
+				if err != nil {
+					logger.Logger.Error("Scheduled backup failed", "error", err)
+					continue
+				}
+				logger.Logger.Info("Database backed up", "path", path, "duration", time.Since(start))
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// Back the database up into a new file of dir, then remove the oldest backups
+func backupInto(src *sql.DB, dir string, keep int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, now.UTC().Format(backupLayout))
+	if err := db.Backup(context.Background(), src, path); err != nil {
+		return "", err
+	}
+	return path, pruneBackups(dir, keep)
+}
+
+// Remove the scheduled backups of dir but the keep most recent ones
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if _, err := time.Parse(backupLayout, entry.Name()); err == nil && entry.Type().IsRegular() {
+			backups = append(backups, entry.Name())
+		}
+	}
+	slices.Sort(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/db"
+	"github.com/flmailla/resume/logger"
+)
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"resume-20261017T030000Z.db",
+		"resume-20261019T030000Z.db",
+		"resume-20261018T030000Z.db",
+		"resume-20261019T030000Z.db.tmp",
+		"notes.txt",
+	}
+	for _, name := range names {
+		writeFile(t, filepath.Join(dir, name), "")
+	}
+
+	if err := pruneBackups(dir, 2); err != nil {
+		t.Fatalf("pruneBackups() = %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{
+		"notes.txt",
+		"resume-20261018T030000Z.db",
+		"resume-20261019T030000Z.db",
+		"resume-20261019T030000Z.db.tmp",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be left, got %v", want, got)
+	}
+}
+
+func TestScheduleBackups(t *testing.T) {
+	logger.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	stop := scheduleBackups(conn, dir, time.Second, 1)
+	time.Sleep(2500 * time.Millisecond)
+	stop()
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "resume-*.db"))
+	if len(backups) != 1 {
+		t.Fatalf("expected the last backup only, got %v", backups)
+	}
+	backup, err := sql.Open("sqlite3", backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	var version int
+	if err := backup.QueryRow("PRAGMA user_version").Scan(&version); err != nil || version != db.SchemaVersion {
+		t.Errorf("expected a backup at version %d, got %d, %v", db.SchemaVersion, version, err)
+	}
+}
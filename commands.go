@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/flmailla/resume/config"
+	"github.com/flmailla/resume/db"
+	"github.com/flmailla/resume/internal/auth"
+)
+
+const commandsUsage string = `usage:
+  resume [flags]                         start the API server
+  resume [flags] <command>               run an administrative command
+  resume --print-config                  print the effective config, secrets masked
+  resume apikey create <name> [scope...] create an API key
+  resume apikey revoke <name>            revoke an API key
+  resume apikey list                     list the API keys
+  resume client create <name> [scope...] register an OAuth2 client
+  resume client revoke <client_id>       revoke an OAuth2 client
+  resume backup --out <file>             copy the SQLite database, consistent while serving
+  resume restore --in <file>             check a backup then swap it in, the server stopped
+  resume token <subject> [scope...]      sign a local HS256 token (needs auth.hmac_secret)`
+
+// Run an administrative command and return the process exit code
+func runCommand(cfg *config.Config, args []string) int {
+	var err error
+	switch {
+	case len(args) >= 3 && args[0] == "apikey" && args[1] == "create":
+		err = createAPIKey(cfg, args[2], args[3:])
+	case len(args) == 3 && args[0] == "apikey" && args[1] == "revoke":
+		err = revokeAPIKey(cfg, args[2])
+	case len(args) == 2 && args[0] == "apikey" && args[1] == "list":
+		err = listAPIKeys(cfg)
+	case len(args) >= 3 && args[0] == "client" && args[1] == "create":
+		err = createClient(cfg, args[2], args[3:])
+	case len(args) == 3 && args[0] == "client" && args[1] == "revoke":
+		err = revokeClient(cfg, args[2])
+	case len(args) >= 1 && args[0] == "backup":
+		err = backupDatabase(cfg, args[1:])
+	case len(args) >= 1 && args[0] == "restore":
+		err = restoreDatabase(cfg, args[1:])
+	case len(args) >= 2 && args[0] == "token":
+		err = signToken(cfg, args[1], args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, commandsUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// Open the database for a command
+func openStore(cfg *config.Config) (*db.Store, error) {
+	if cfg.Database.Storage == "memory" {
+		return nil, errors.New("commands need a database, the memory storage is not persisted")
+	}
+	if err := db.InitDB(cfg.Database.DSN, sqliteOptions(cfg.Database.SQLite)); err != nil {
+		return nil, err
+	}
+	return db.NewStoreFromPools(db.DB, db.ReadDB, cfg.Database.QueryTimeout), nil
+}
+
+func createAPIKey(cfg *config.Config, name string, scopes []string) error {
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.CreateAPIKey(context.Background(), name, hash, scopes); err != nil {
+		return fmt.Errorf("failed to store api key: %w", err)
+	}
+
+	fmt.Println(key)
+	return nil
+}
+
+func revokeAPIKey(cfg *config.Config, name string) error {
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	return store.RevokeAPIKey(context.Background(), name)
+}
+
+func listAPIKeys(cfg *config.Config) error {
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	apiKeys, err := store.GetAPIKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, apiKey := range apiKeys {
+		status := "active"
+		if apiKey.RevokedAt != nil {
+			status = "revoked"
+		}
+		lastUsed := "never"
+		if apiKey.LastUsedAt != nil {
+			lastUsed = apiKey.LastUsedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%s\t%s\tscopes=%v\tcreated=%s\tlast_used=%s\n",
+			apiKey.Name, status, apiKey.Scopes, apiKey.CreatedAt.Format(time.RFC3339), lastUsed)
+	}
+	return nil
+}
+
+func createClient(cfg *config.Config, name string, scopes []string) error {
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	clientId, clientSecret, hash, err := auth.GenerateClientCredentials()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.CreateOAuthClient(context.Background(), clientId, name, hash, scopes); err != nil {
+		return fmt.Errorf("failed to store client: %w", err)
+	}
+
+	fmt.Printf("client_id=%s\nclient_secret=%s\n", clientId, clientSecret)
+	return nil
+}
+
+func revokeClient(cfg *config.Config, clientId string) error {
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	return store.RevokeOAuthClient(context.Background(), clientId)
+}
+
+func backupDatabase(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	out := flags.String("out", "", "backup file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" || flags.NArg() > 0 {
+		return errors.New("usage: resume backup --out <file>")
+	}
+
+	if _, err := openStore(cfg); err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	if err := db.Backup(context.Background(), backupSource(), *out); err != nil {
+		return err
+	}
+	fmt.Println(*out)
+	return nil
+}
+
+func restoreDatabase(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	in := flags.String("in", "", "backup file to restore")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" || flags.NArg() > 0 {
+		return errors.New("usage: resume restore --in <file>")
+	}
+	if cfg.Database.Storage == "memory" {
+		return errors.New("the memory storage has no database to restore")
+	}
+
+	return db.Restore(context.Background(), *in, cfg.Database.DSN)
+}
+
+func signToken(cfg *config.Config, subject string, scopes []string) error {
+	if cfg.Auth.HMACSecret == "" {
+		return fmt.Errorf("auth.hmac_secret is not set")
+	}
+
+	token, err := auth.NewHMACAuthenticator([]byte(cfg.Auth.HMACSecret)).Sign(subject, scopes, time.Hour)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(token)
+	return nil
+}
@@ -0,0 +1,544 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/internal/middleware"
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/internal/tracing"
+	"github.com/flmailla/resume/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// Settings of the service
+// Every leaf can be set in the config file (yaml tag),
+// the environment (env tag) or on the command line (flag tag)
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Log      LogConfig      `yaml:"log"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Auth     AuthConfig     `yaml:"auth"`
+}
+
+type ServerConfig struct {
+	Addr              string            `yaml:"addr" env:"RESUME_ADDR" flag:"addr" usage:"listen address"`
+	ReadTimeout       time.Duration     `yaml:"read_timeout" env:"RESUME_READ_TIMEOUT" flag:"read-timeout" usage:"deadline to read a whole request, body included"`
+	ReadHeaderTimeout time.Duration     `yaml:"read_header_timeout" env:"RESUME_READ_HEADER_TIMEOUT" flag:"read-header-timeout" usage:"deadline to read the request headers"`
+	WriteTimeout      time.Duration     `yaml:"write_timeout" env:"RESUME_WRITE_TIMEOUT" flag:"write-timeout" usage:"deadline to write a response"`
+	IdleTimeout       time.Duration     `yaml:"idle_timeout" env:"RESUME_IDLE_TIMEOUT" flag:"idle-timeout" usage:"time a keep-alive connection waits for the next request"`
+	MaxHeaderBytes    int               `yaml:"max_header_bytes" env:"RESUME_MAX_HEADER_BYTES" flag:"max-header-bytes" usage:"maximum size of the request headers"`
+	MaxBodyBytes      int64             `yaml:"max_body_bytes" env:"RESUME_MAX_BODY_BYTES" flag:"max-body-bytes" usage:"maximum size of a request body"`
+	Routes            []RouteConfig     `yaml:"routes"`
+	ShutdownDelay     time.Duration     `yaml:"shutdown_delay" env:"RESUME_SHUTDOWN_DELAY" flag:"shutdown-delay" usage:"time reported not ready before draining, for load balancers to notice"`
+	ShutdownTimeout   time.Duration     `yaml:"shutdown_timeout" env:"RESUME_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"deadline to drain the in-flight requests on shutdown"`
+	PublicRoutes      []string          `yaml:"public_routes" env:"RESUME_PUBLIC_ROUTES" flag:"public-routes" usage:"routes served to anonymous callers, comma separated ServeMux patterns"`
+	Docs              bool              `yaml:"docs" env:"RESUME_DOCS" flag:"docs" usage:"serve the API documentation on /docs and its spec on /openapi.json, to anonymous callers"`
+	Cache             CacheConfig       `yaml:"cache"`
+	Compression       CompressionConfig `yaml:"compression"`
+	RateLimit         RateLimitConfig   `yaml:"rate_limit"`
+	CORS              CORSConfig        `yaml:"cors"`
+	TLS               TLSConfig         `yaml:"tls"`
+}
+
+// Caching of the resume responses, by the clients and in memory
+type CacheConfig struct {
+	MaxAge  time.Duration `yaml:"max_age" env:"RESUME_CACHE_MAX_AGE" flag:"cache-max-age" usage:"Cache-Control max-age of the resume responses, 0 to revalidate every time"`
+	Entries int           `yaml:"entries" env:"RESUME_CACHE_ENTRIES" flag:"cache-entries" usage:"resume responses kept in memory, 0 to disable the cache"`
+	TTL     time.Duration `yaml:"ttl" env:"RESUME_CACHE_TTL" flag:"cache-ttl" usage:"time a response is kept in memory, bounding how late writes of other processes show"`
+}
+
+// Compression of the responses, negotiated with Accept-Encoding
+type CompressionConfig struct {
+	Encodings    []string `yaml:"encodings" env:"RESUME_COMPRESSION_ENCODINGS" flag:"compression-encodings" usage:"encodings by order of preference among br, zstd and gzip, comma separated, empty to disable compression"`
+	MinSize      int      `yaml:"min_size" env:"RESUME_COMPRESSION_MIN_SIZE" flag:"compression-min-size" usage:"size from which a response body is compressed, in bytes"`
+	ContentTypes []string `yaml:"content_types" env:"RESUME_COMPRESSION_CONTENT_TYPES" flag:"compression-content-types" usage:"media types compressed, comma separated, text/* matching every text type"`
+}
+
+// Requests allowed to a client per period, the client being its principal
+// or, for anonymous requests, its address
+type RateLimitConfig struct {
+	Requests           int                    `yaml:"requests" env:"RESUME_RATE_LIMIT_REQUESTS" flag:"rate-limit-requests" usage:"requests a client can make per period, 0 to disable the limit"`
+	Period             time.Duration          `yaml:"period" env:"RESUME_RATE_LIMIT_PERIOD" flag:"rate-limit-period" usage:"period the requests of a client are limited over"`
+	Routes             []RouteRateLimitConfig `yaml:"routes"`
+	FailedAuthRequests int                    `yaml:"failed_auth_requests" env:"RESUME_RATE_LIMIT_FAILED_AUTH_REQUESTS" flag:"rate-limit-failed-auth-requests" usage:"rejected credentials an address can send per failed_auth_period before being refused, 0 to disable the limit"`
+	FailedAuthPeriod   time.Duration          `yaml:"failed_auth_period" env:"RESUME_RATE_LIMIT_FAILED_AUTH_PERIOD" flag:"rate-limit-failed-auth-period" usage:"period the rejected credentials of an address are limited over"`
+	TrustedProxies     []string               `yaml:"trusted_proxies" env:"RESUME_RATE_LIMIT_TRUSTED_PROXIES" flag:"rate-limit-trusted-proxies" usage:"addresses or CIDR prefixes of the reverse proxies whose X-Forwarded-For or X-Real-IP is trusted, comma separated"`
+}
+
+// Cross-origin requests allowed to browsers, disabled without origins
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"RESUME_CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" usage:"origins allowed to call the API from a browser, comma separated, a * standing for any part, e.g. https://*.example.com, empty to disable CORS"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"RESUME_CORS_ALLOWED_METHODS" flag:"cors-allowed-methods" usage:"methods allowed to cross-origin requests, comma separated"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"RESUME_CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers" usage:"request headers allowed to cross-origin requests, comma separated"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"RESUME_CORS_EXPOSED_HEADERS" flag:"cors-exposed-headers" usage:"response headers readable by cross-origin callers, comma separated"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"RESUME_CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials" usage:"allow cross-origin requests with cookies or an Authorization managed by the browser"`
+	MaxAge           time.Duration `yaml:"max_age" env:"RESUME_CORS_MAX_AGE" flag:"cors-max-age" usage:"time browsers can keep a preflight response"`
+}
+
+// Rate limit of the routes matching a http.ServeMux pattern, counted apart
+// from the other routes. Only set in the config file.
+type RouteRateLimitConfig struct {
+	Pattern  string        `yaml:"pattern"`
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+}
+
+// Limits of the routes matching a http.ServeMux pattern,
+// a zero value keeps the server one. Only set in the config file.
+type RouteConfig struct {
+	Pattern      string        `yaml:"pattern"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	MaxBodyBytes int64         `yaml:"max_body_bytes"`
+}
+
+type TLSConfig struct {
+	CertFile         string   `yaml:"cert_file" env:"RESUME_TLS_CERT_FILE" flag:"tls-cert" usage:"server certificate, enables TLS"`
+	KeyFile          string   `yaml:"key_file" env:"RESUME_TLS_KEY_FILE" flag:"tls-key" usage:"server private key"`
+	ClientCAFile     string   `yaml:"client_ca_file" env:"RESUME_TLS_CLIENT_CA_FILE" flag:"tls-client-ca" usage:"CA bundle verifying client certificates"`
+	ClientIdentities []string `yaml:"client_identities" env:"RESUME_TLS_CLIENT_IDENTITIES" flag:"tls-client-identities" usage:"client certificate identities, comma separated <kind>:<value>=<principal> [scope...]"`
+}
+
+type DatabaseConfig struct {
+	Storage      string        `yaml:"storage" env:"RESUME_STORAGE" flag:"storage" usage:"sql for the database of dsn, or memory for an ephemeral demo store"`
+	DSN          string        `yaml:"dsn" env:"RESUME_DB_DSN" flag:"db" usage:"SQLite database file, or postgres:// URL" secret:"password"`
+	QueryTimeout time.Duration `yaml:"query_timeout" env:"RESUME_DB_QUERY_TIMEOUT" flag:"db-query-timeout" usage:"deadline of a database statement, 0 to only follow the request"`
+	SQLite       SQLiteConfig  `yaml:"sqlite"`
+	Backup       BackupConfig  `yaml:"backup"`
+}
+
+// Scheduled backups of the SQLite database
+type BackupConfig struct {
+	Dir      string        `yaml:"dir" env:"RESUME_BACKUP_DIR" flag:"backup-dir" usage:"directory of the scheduled backups, empty to disable them"`
+	Interval time.Duration `yaml:"interval" env:"RESUME_BACKUP_INTERVAL" flag:"backup-interval" usage:"time between two scheduled backups"`
+	Keep     int           `yaml:"keep" env:"RESUME_BACKUP_KEEP" flag:"backup-keep" usage:"scheduled backups kept, the oldest being removed"`
+}
+
+// Tuning of the SQLite connections, see https://www.sqlite.org/pragma.html
+type SQLiteConfig struct {
+	JournalMode     string        `yaml:"journal_mode" env:"RESUME_SQLITE_JOURNAL_MODE" flag:"sqlite-journal-mode" usage:"journal mode: delete, truncate, persist, memory, wal or off"`
+	Synchronous     string        `yaml:"synchronous" env:"RESUME_SQLITE_SYNCHRONOUS" flag:"sqlite-synchronous" usage:"synchronous mode: off, normal, full or extra"`
+	BusyTimeout     time.Duration `yaml:"busy_timeout" env:"RESUME_SQLITE_BUSY_TIMEOUT" flag:"sqlite-busy-timeout" usage:"wait for a locked database before failing"`
+	ForeignKeys     bool          `yaml:"foreign_keys" env:"RESUME_SQLITE_FOREIGN_KEYS" flag:"sqlite-foreign-keys" usage:"enforce the foreign keys"`
+	ReadConnections int           `yaml:"read_connections" env:"RESUME_SQLITE_READ_CONNECTIONS" flag:"sqlite-read-connections" usage:"read only connections, 0 to read from the single write connection"`
+	IntegrityCheck  bool          `yaml:"integrity_check" env:"RESUME_SQLITE_INTEGRITY_CHECK" flag:"sqlite-integrity-check" usage:"check the integrity of the database file at startup"`
+}
+
+type LogConfig struct {
+	Level      string `yaml:"level" env:"RESUME_LOG_LEVEL" flag:"log-level" usage:"minimum level logged: debug, info, warn or error"`
+	Format     string `yaml:"format" env:"RESUME_LOG_FORMAT" flag:"log-format" usage:"log format: json or text"`
+	Stdout     bool   `yaml:"stdout" env:"RESUME_LOG_STDOUT" flag:"log-stdout" usage:"log on stdout"`
+	File       string `yaml:"file" env:"RESUME_LOG_FILE" flag:"log-file" usage:"rotated log file, empty to log on stdout only"`
+	MaxSizeMB  int    `yaml:"max_size_mb" env:"RESUME_LOG_MAX_SIZE_MB" flag:"log-max-size-mb" usage:"size of the log file triggering its rotation, in megabytes"`
+	MaxBackups int    `yaml:"max_backups" env:"RESUME_LOG_MAX_BACKUPS" flag:"log-max-backups" usage:"rotated log files kept, 0 to keep them all"`
+	MaxAgeDays int    `yaml:"max_age_days" env:"RESUME_LOG_MAX_AGE_DAYS" flag:"log-max-age-days" usage:"days a rotated log file is kept, 0 to keep them forever"`
+	Compress   bool   `yaml:"compress" env:"RESUME_LOG_COMPRESS" flag:"log-compress" usage:"gzip the rotated log files"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" env:"RESUME_TRACING_EXPORTER" flag:"tracing-exporter" usage:"span exporter: none, stdout or otlp"`
+	Endpoint    string `yaml:"endpoint" env:"RESUME_TRACING_ENDPOINT" flag:"tracing-endpoint" usage:"OTLP/HTTP traces URL, OTEL_EXPORTER_OTLP_* variables being used when empty"`
+	ServiceName string `yaml:"service_name" env:"RESUME_TRACING_SERVICE_NAME" flag:"tracing-service-name" usage:"service name reported in the spans"`
+}
+
+type AuthConfig struct {
+	JWKSURL       string              `yaml:"jwks_url" env:"RESUME_JWKS_URL" flag:"jwks-url" usage:"JSON Web Key Set of the identity provider, empty to accept none of its tokens"`
+	Issuer        string              `yaml:"issuer" env:"RESUME_JWT_ISSUER" flag:"jwt-issuer" usage:"expected issuer of the identity provider tokens"`
+	Audience      string              `yaml:"audience" env:"RESUME_JWT_AUDIENCE" flag:"jwt-audience" usage:"expected audience of the identity provider tokens"`
+	HMACSecret    string              `yaml:"hmac_secret" env:"RESUME_HMAC_SECRET" flag:"hmac-secret" usage:"secret of the local HS256 tokens" secret:"true"`
+	OAuth         OAuthConfig         `yaml:"oauth"`
+	Introspection IntrospectionConfig `yaml:"introspection"`
+}
+
+type OAuthConfig struct {
+	Issuer  string `yaml:"issuer" env:"RESUME_OAUTH_ISSUER" flag:"oauth-issuer" usage:"issuer URL, enables the embedded token endpoint"`
+	KeyFile string `yaml:"key_file" env:"RESUME_OAUTH_KEY_FILE" flag:"oauth-key-file" usage:"PEM RSA key signing the tokens, ephemeral when empty"`
+}
+
+type IntrospectionConfig struct {
+	Endpoint     string `yaml:"endpoint" env:"RESUME_INTROSPECTION_ENDPOINT" flag:"introspection-endpoint" usage:"RFC 7662 introspection endpoint"`
+	ClientID     string `yaml:"client_id" env:"RESUME_INTROSPECTION_CLIENT_ID" flag:"introspection-client-id" usage:"client id calling the introspection endpoint"`
+	ClientSecret string `yaml:"client_secret" env:"RESUME_INTROSPECTION_CLIENT_SECRET" flag:"introspection-client-secret" usage:"client secret calling the introspection endpoint" secret:"true"`
+	Issuer       string `yaml:"issuer" env:"RESUME_INTROSPECTION_ISSUER" flag:"introspection-issuer" usage:"issuer whose JWT shaped tokens are introspected"`
+}
+
+// Settings used when nothing else is given
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              "localhost:8090",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			MaxBodyBytes:      1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			Docs:              true,
+			Cache: CacheConfig{
+				MaxAge:  time.Minute,
+				Entries: 512,
+				TTL:     5 * time.Minute,
+			},
+			Compression: CompressionConfig{
+				Encodings:    middleware.Encodings(),
+				MinSize:      1024,
+				ContentTypes: []string{"application/json", "text/*"},
+			},
+			RateLimit: RateLimitConfig{
+				Requests:           120,
+				Period:             time.Minute,
+				FailedAuthRequests: 10,
+				FailedAuthPeriod:   10 * time.Minute,
+			},
+			CORS: CORSConfig{
+				AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+				AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "If-None-Match", "If-Modified-Since"},
+				ExposedHeaders: []string{"ETag", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "X-Request-ID"},
+				MaxAge:         10 * time.Minute,
+			},
+		},
+		Database: DatabaseConfig{
+			Storage:      "sql",
+			DSN:          "./resume.db",
+			QueryTimeout: 5 * time.Second,
+			SQLite: SQLiteConfig{
+				JournalMode:     "wal",
+				Synchronous:     "normal",
+				BusyTimeout:     5 * time.Second,
+				ForeignKeys:     true,
+				ReadConnections: 4,
+				IntegrityCheck:  true,
+			},
+			Backup: BackupConfig{
+				Interval: 24 * time.Hour,
+				Keep:     7,
+			},
+		},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     "json",
+			Stdout:     true,
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+			Compress:   true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "resume",
+		},
+		Auth: AuthConfig{
+			JWKSURL:  auth.DefaultJWKSURL,
+			Issuer:   auth.DefaultIssuer,
+			Audience: auth.DefaultAudience,
+		},
+	}
+}
+
+// Check the settings, reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(field string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		invalid("server.addr", "must be host:port, got %q", c.Server.Addr)
+	}
+
+	for _, timeout := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			invalid(timeout.field, "must not be negative")
+		}
+	}
+	if c.Server.MaxHeaderBytes < 0 || c.Server.MaxBodyBytes < 0 {
+		invalid("server", "max_header_bytes and max_body_bytes must not be negative")
+	}
+	for i, route := range c.Server.Routes {
+		field := fmt.Sprintf("server.routes[%d]", i)
+		if err := checkPattern(route.Pattern); err != nil {
+			invalid(field, "invalid pattern %q: %v", route.Pattern, err)
+		}
+		if route.ReadTimeout < 0 || route.WriteTimeout < 0 || route.MaxBodyBytes < 0 {
+			invalid(field, "limits must not be negative")
+		}
+	}
+
+	if c.Server.ShutdownDelay < 0 {
+		invalid("server.shutdown_delay", "must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout", "must be positive")
+	}
+
+	for _, pattern := range c.Server.PublicRoutes {
+		if err := checkPattern(pattern); err != nil {
+			invalid("server.public_routes", "invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	if c.Server.Cache.MaxAge < 0 {
+		invalid("server.cache.max_age", "must not be negative")
+	}
+	if c.Server.Cache.Entries < 0 {
+		invalid("server.cache.entries", "must not be negative")
+	}
+	if c.Server.Cache.Entries > 0 && c.Server.Cache.TTL <= 0 {
+		invalid("server.cache.ttl", "must be positive")
+	}
+
+	for _, encoding := range c.Server.Compression.Encodings {
+		if !slices.Contains(middleware.Encodings(), encoding) {
+			invalid("server.compression.encodings", "must be among %s, got %q", strings.Join(middleware.Encodings(), ", "), encoding)
+		}
+	}
+	if c.Server.Compression.MinSize < 0 {
+		invalid("server.compression.min_size", "must not be negative")
+	}
+	for _, contentType := range c.Server.Compression.ContentTypes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if family, subtype, _ := strings.Cut(mediaType, "/"); err != nil || family == "" || subtype == "" {
+			invalid("server.compression.content_types", "invalid media type %q", contentType)
+		}
+	}
+
+	rateLimit := c.Server.RateLimit
+	if rateLimit.Requests < 0 || rateLimit.FailedAuthRequests < 0 {
+		invalid("server.rate_limit", "requests and failed_auth_requests must not be negative")
+	}
+	if rateLimit.Requests > 0 && rateLimit.Period <= 0 {
+		invalid("server.rate_limit.period", "must be positive")
+	}
+	if rateLimit.FailedAuthRequests > 0 && rateLimit.FailedAuthPeriod <= 0 {
+		invalid("server.rate_limit.failed_auth_period", "must be positive")
+	}
+	if _, err := ratelimit.ParseProxies(rateLimit.TrustedProxies); err != nil {
+		invalid("server.rate_limit.trusted_proxies", "%v", err)
+	}
+	for i, route := range rateLimit.Routes {
+		field := fmt.Sprintf("server.rate_limit.routes[%d]", i)
+		if err := checkPattern(route.Pattern); err != nil {
+			invalid(field, "invalid pattern %q: %v", route.Pattern, err)
+		}
+		if route.Requests <= 0 || route.Period <= 0 {
+			invalid(field, "requests and period must be positive")
+		}
+	}
+
+	cors := c.Server.CORS
+	for _, origin := range cors.AllowedOrigins {
+		if err := checkOrigin(origin); err != nil {
+			invalid("server.cors.allowed_origins", "invalid origin %q: %v", origin, err)
+		}
+	}
+	if cors.AllowCredentials && slices.Contains(cors.AllowedOrigins, "*") {
+		invalid("server.cors.allowed_origins", "* is not allowed with allow_credentials, list the origins")
+	}
+	if cors.MaxAge < 0 {
+		invalid("server.cors.max_age", "must not be negative")
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		invalid("server.tls", "cert_file and key_file must be set together")
+	}
+	if tls.ClientCAFile != "" && tls.CertFile == "" {
+		invalid("server.tls.client_ca_file", "requires TLS to be enabled")
+	}
+	if len(tls.ClientIdentities) > 0 && tls.ClientCAFile == "" {
+		invalid("server.tls.client_identities", "requires client_ca_file")
+	}
+	if _, err := auth.ParseCertIdentities(tls.CertIdentitiesSpec()); err != nil {
+		invalid("server.tls.client_identities", "%v", err)
+	}
+	for _, file := range []struct{ field, path string }{
+		{"server.tls.cert_file", tls.CertFile},
+		{"server.tls.key_file", tls.KeyFile},
+		{"server.tls.client_ca_file", tls.ClientCAFile},
+		{"auth.oauth.key_file", c.Auth.OAuth.KeyFile},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			invalid(file.field, "%v", err)
+		}
+	}
+
+	if c.Database.Storage != "sql" && c.Database.Storage != "memory" {
+		invalid("database.storage", "must be sql or memory, got %q", c.Database.Storage)
+	}
+	if c.Database.DSN == "" {
+		invalid("database.dsn", "must not be empty")
+	}
+	if c.Database.QueryTimeout < 0 {
+		invalid("database.query_timeout", "must not be negative")
+	}
+	journalModes := []string{"delete", "truncate", "persist", "memory", "wal", "off"}
+	if !slices.Contains(journalModes, strings.ToLower(c.Database.SQLite.JournalMode)) {
+		invalid("database.sqlite.journal_mode", "must be one of %s, got %q", strings.Join(journalModes, ", "), c.Database.SQLite.JournalMode)
+	}
+	synchronousModes := []string{"off", "normal", "full", "extra"}
+	if !slices.Contains(synchronousModes, strings.ToLower(c.Database.SQLite.Synchronous)) {
+		invalid("database.sqlite.synchronous", "must be one of %s, got %q", strings.Join(synchronousModes, ", "), c.Database.SQLite.Synchronous)
+	}
+	if c.Database.SQLite.BusyTimeout < 0 {
+		invalid("database.sqlite.busy_timeout", "must not be negative")
+	}
+	if c.Database.SQLite.ReadConnections < 0 {
+		invalid("database.sqlite.read_connections", "must not be negative")
+	}
+	if c.Database.Backup.Dir != "" {
+		if c.Database.Backup.Interval <= 0 {
+			invalid("database.backup.interval", "must be positive")
+		}
+		if c.Database.Backup.Keep < 1 {
+			invalid("database.backup.keep", "must be at least 1")
+		}
+	}
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		invalid("log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		invalid("log.format", "must be json or text, got %q", c.Log.Format)
+	}
+	if !c.Log.Stdout && c.Log.File == "" {
+		invalid("log", "stdout or file must be enabled")
+	}
+	if c.Log.MaxSizeMB <= 0 {
+		invalid("log.max_size_mb", "must be positive")
+	}
+	if c.Log.MaxBackups < 0 || c.Log.MaxAgeDays < 0 {
+		invalid("log", "max_backups and max_age_days must not be negative")
+	}
+
+	if !slices.Contains(tracing.Exporters(), c.Tracing.Exporter) {
+		invalid("tracing.exporter", "must be one of %s, got %q", strings.Join(tracing.Exporters(), ", "), c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" {
+		if err := checkURL(c.Tracing.Endpoint); err != nil {
+			invalid("tracing.endpoint", "%v", err)
+		}
+	}
+
+	if c.Auth.JWKSURL != "" {
+		if err := checkURL(c.Auth.JWKSURL); err != nil {
+			invalid("auth.jwks_url", "%v", err)
+		}
+		if c.Auth.Issuer == "" || c.Auth.Audience == "" {
+			invalid("auth", "issuer and audience must not be empty")
+		}
+	}
+	if c.Auth.OAuth.Issuer != "" {
+		if err := checkURL(c.Auth.OAuth.Issuer); err != nil {
+			invalid("auth.oauth.issuer", "%v", err)
+		}
+	}
+	if c.Auth.Introspection.Endpoint != "" {
+		if err := checkURL(c.Auth.Introspection.Endpoint); err != nil {
+			invalid("auth.introspection.endpoint", "%v", err)
+		}
+		if c.Auth.Introspection.ClientID == "" {
+			invalid("auth.introspection.client_id", "required by the introspection endpoint")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Client identities in the format read by auth.ParseCertIdentities
+func (t TLSConfig) CertIdentitiesSpec() string {
+	return strings.Join(t.ClientIdentities, ";")
+}
+
+// Effective settings as YAML, secrets masked
+func (c *Config) String() string {
+	masked := *c
+	maskSecrets(reflect.ValueOf(&masked).Elem())
+	data, err := yaml.Marshal(&masked)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func maskSecrets(v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			maskSecrets(field)
+		case t.Field(i).Tag.Get("secret") == "true" && field.String() != "":
+			field.SetString("********")
+		case t.Field(i).Tag.Get("secret") == "password":
+			// URLs keep everything but their password
+			if u, err := url.Parse(field.String()); err == nil {
+				field.SetString(u.Redacted())
+			}
+		}
+	}
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("must be an absolute http(s) URL, got %q", raw)
+	}
+	return nil
+}
+
+// Check an origin written as scheme://host[:port], or *, with at most
+// one * standing for a part of it
+func checkOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	if strings.Count(origin, "*") > 1 {
+		return errors.New("at most one * allowed")
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return errors.New("must be scheme://host[:port]")
+	}
+	return nil
+}
+
+// Check a http.ServeMux pattern, which panics on invalid ones
+func checkPattern(pattern string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	http.NewServeMux().Handle(pattern, http.NotFoundHandler())
+	return nil
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Write a config file in a temporary directory
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "resume.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, options, args, err := Load(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("expected default config %+v, got %+v", Default(), cfg)
+	}
+
+	if options.PrintConfig || len(args) != 0 {
+		t.Errorf("unexpected options %+v and args %v", options, args)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  addr: file:1
+  public_routes: ["GET /skills"]
+database:
+  dsn: file.db
+log:
+  file: file.log
+`)
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		wantAddr string
+		wantDB   string
+		wantLog  string
+		wantArgs []string
+	}{
+		{
+			name:     "file over defaults",
+			args:     []string{"--config", path},
+			wantAddr: "file:1",
+			wantDB:   "file.db",
+			wantLog:  "file.log",
+		},
+		{
+			name:     "env over file",
+			env:      map[string]string{"RESUME_ADDR": "env:2", "RESUME_CONFIG": path},
+			wantAddr: "env:2",
+			wantDB:   "file.db",
+			wantLog:  "file.log",
+		},
+		{
+			name:     "flags over env",
+			env:      map[string]string{"RESUME_ADDR": "env:2", "RESUME_DB_DSN": "env.db"},
+			args:     []string{"--config", path, "--addr", "flag:3", "apikey", "list"},
+			wantAddr: "flag:3",
+			wantDB:   "env.db",
+			wantLog:  "file.log",
+			wantArgs: []string{"apikey", "list"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, _, args, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if cfg.Server.Addr != tt.wantAddr || cfg.Database.DSN != tt.wantDB || cfg.Log.File != tt.wantLog {
+				t.Errorf("unexpected config %+v", cfg)
+			}
+
+			if !reflect.DeepEqual(cfg.Server.PublicRoutes, []string{"GET /skills"}) {
+				t.Errorf("expected public routes from the file, got %v", cfg.Server.PublicRoutes)
+			}
+
+			if len(args) != len(tt.wantArgs) || len(args) > 0 && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("expected args %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.toml")
+	content := `
+[server]
+addr = "file:1"
+public_routes = ["GET /skills"]
+
+[[server.routes]]
+pattern = "GET /profiles/{profile_id}/pdf"
+write_timeout = "2m"
+
+[database]
+query_timeout = "3s"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, _, _, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.Server.Addr != "file:1" || !reflect.DeepEqual(cfg.Server.PublicRoutes, []string{"GET /skills"}) {
+		t.Errorf("unexpected server settings %+v", cfg.Server)
+	}
+	if len(cfg.Server.Routes) != 1 || cfg.Server.Routes[0].WriteTimeout != 2*time.Minute {
+		t.Errorf("unexpected routes %+v", cfg.Server.Routes)
+	}
+	if cfg.Database.QueryTimeout != 3*time.Second {
+		t.Errorf("expected query timeout 3s, got %s", cfg.Database.QueryTimeout)
+	}
+
+	if err := os.WriteFile(path, []byte("[server]\nadr = \"localhost:8090\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if _, _, _, err := Load([]string{"--config", path}); err == nil || !strings.Contains(err.Error(), "adr") {
+		t.Errorf("expected the unknown key to be rejected, got %v", err)
+	}
+}
+
+func TestLoadListFromEnv(t *testing.T) {
+	t.Setenv("RESUME_PUBLIC_ROUTES", "GET /skills, GET /profiles/{profile_id}")
+
+	cfg, _, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []string{"GET /skills", "GET /profiles/{profile_id}"}
+	if !reflect.DeepEqual(cfg.Server.PublicRoutes, want) {
+		t.Errorf("expected public routes %v, got %v", want, cfg.Server.PublicRoutes)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name:    "unknown key in file",
+			content: "server:\n  adr: localhost:8090\n",
+			wantErr: []string{"adr"},
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"--unknown"},
+			wantErr: []string{"unknown"},
+		},
+		{
+			name:    "invalid route limits",
+			content: "server:\n  routes:\n    - pattern: GET /a/{\n    - pattern: GET /pdf\n      write_timeout: -1s\n",
+			wantErr: []string{"server.routes[0]: invalid pattern", "server.routes[1]: limits"},
+		},
+		{
+			name:    "invalid log settings",
+			args:    []string{"--log-level", "verbose", "--log-format", "xml", "--log-stdout=false"},
+			wantErr: []string{"log.level", "log.format", "log: stdout or file"},
+		},
+		{
+			name:    "invalid storage",
+			args:    []string{"--storage", "redis"},
+			wantErr: []string{"database.storage"},
+		},
+		{
+			name:    "invalid sqlite settings",
+			content: "database:\n  sqlite:\n    journal_mode: fast\n    synchronous: sometimes\n    read_connections: -1\n",
+			wantErr: []string{"database.sqlite.journal_mode", "database.sqlite.synchronous", "database.sqlite.read_connections"},
+		},
+		{
+			name:    "invalid backup schedule",
+			args:    []string{"--backup-dir", "/var/backups/resume", "--backup-interval", "0s", "--backup-keep", "0"},
+			wantErr: []string{"database.backup.interval", "database.backup.keep"},
+		},
+		{
+			name:    "invalid cache",
+			content: "server:\n  cache:\n    max_age: -1s\n    ttl: 0s\n",
+			wantErr: []string{"server.cache.max_age", "server.cache.ttl"},
+		},
+		{
+			name:    "invalid compression",
+			args:    []string{"--compression-encodings", "br,deflate", "--compression-min-size", "-1", "--compression-content-types", "text/*,json"},
+			wantErr: []string{"server.compression.encodings", "server.compression.min_size", "server.compression.content_types"},
+		},
+		{
+			name:    "invalid rate limits",
+			content: "server:\n  rate_limit:\n    period: 0s\n    failed_auth_period: 0s\n    routes:\n      - pattern: GET /admin/backup\n",
+			wantErr: []string{"server.rate_limit.period", "server.rate_limit.failed_auth_period", "server.rate_limit.routes[0]"},
+		},
+		{
+			name:    "invalid trusted proxies",
+			args:    []string{"--rate-limit-trusted-proxies", "10.0.0.0/8,proxy.internal"},
+			wantErr: []string{`server.rate_limit.trusted_proxies: "proxy.internal"`},
+		},
+		{
+			name:    "invalid cors",
+			args:    []string{"--cors-allowed-origins", "https://*.example.com,*,example.com,https://*.*.example.com,https://app.example.com/", "--cors-max-age", "-1s"},
+			wantErr: []string{`"example.com"`, `"https://*.*.example.com"`, `"https://app.example.com/"`, "server.cors.max_age"},
+		},
+		{
+			name:    "cors credentials for any origin",
+			args:    []string{"--cors-allowed-origins", "*", "--cors-allow-credentials", "true"},
+			wantErr: []string{"server.cors.allowed_origins: * is not allowed with allow_credentials"},
+		},
+		{
+			name: "every problem reported",
+			args: []string{
+				"--addr", "8090",
+				"--public-routes", "GET /a/{",
+				"--tls-key", "key.pem",
+				"--jwks-url", "keys",
+				"--introspection-endpoint", "https://gateway/introspect",
+			},
+			wantErr: []string{
+				"server.addr",
+				"server.public_routes",
+				"server.tls: cert_file and key_file",
+				"server.tls.key_file",
+				"auth.jwks_url",
+				"auth.introspection.client_id",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.content != "" {
+				args = append([]string{"--config", writeConfigFile(t, tt.content)}, args...)
+			}
+
+			_, _, _, err := Load(args)
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to mention %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStringMasksSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.HMACSecret = "hmac-secret-value"
+	cfg.Auth.Introspection.ClientSecret = "client-secret-value"
+	cfg.Database.DSN = "postgres://resume:dsn-secret-value@db:5432/resume"
+
+	out := cfg.String()
+
+	for _, secret := range []string{"hmac-secret-value", "client-secret-value", "dsn-secret-value"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %s to be masked, got\n%s", secret, out)
+		}
+	}
+
+	if !strings.Contains(out, "hmac_secret: '********'") || !strings.Contains(out, "addr: localhost:8090") ||
+		!strings.Contains(out, "dsn: postgres://resume:xxxxx@db:5432/resume") {
+		t.Errorf("unexpected effective config\n%s", out)
+	}
+
+	if cfg.Auth.HMACSecret != "hmac-secret-value" {
+		t.Error("expected the config itself to keep its secrets")
+	}
+}
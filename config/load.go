@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variable holding the config file path
+const configEnv string = "RESUME_CONFIG"
+
+// Settings leaf, reachable from every source
+type setting struct {
+	value reflect.Value
+	field reflect.StructField
+	path  string
+}
+
+// Options of the command line which are not settings
+type Options struct {
+	PrintConfig bool
+}
+
+// Build the effective settings, from the lowest to the highest precedence:
+// defaults, config file, environment variables and command line flags.
+// The arguments left after the flags are returned, for subcommands.
+func Load(args []string) (*Config, *Options, []string, error) {
+	cfg := Default()
+	settings := collectSettings(reflect.ValueOf(cfg).Elem(), "")
+	options := &Options{}
+
+	flags := flag.NewFlagSet("resume", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(configEnv), "YAML, or TOML with a .toml extension, config file")
+	flags.BoolVar(&options.PrintConfig, "print-config", false, "print the effective config, secrets masked, and exit")
+
+	// Flags are only recorded here, they are applied last
+	type flagValue struct {
+		setting setting
+		raw     string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		name := s.field.Tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		flags.Func(name, s.field.Tag.Get("usage"), func(raw string) error {
+			flagValues = append(flagValues, flagValue{setting: s, raw: raw})
+			return nil
+		})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		name := s.field.Tag.Get("env")
+		if raw, exists := os.LookupEnv(name); exists && name != "" {
+			if err := setValue(s.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", s.path, name, err))
+			}
+		}
+	}
+
+	for _, f := range flagValues {
+		if err := setValue(f.setting.value, f.raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s (--%s): %w", f.setting.path, f.setting.field.Tag.Get("flag"), err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, options, flags.Args(), nil
+}
+
+// Read a YAML config file, or a TOML one when its extension is .toml,
+// unknown keys are rejected
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	// TOML tables map to the same keys, checked by the YAML decoding
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var tables map[string]any
+		if err := toml.Unmarshal(data, &tables); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(tables); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// List the leaves of the settings tree
+func collectSettings(v reflect.Value, prefix string) []setting {
+	var settings []setting
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		path := prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[time.Duration]() {
+			settings = append(settings, collectSettings(v.Field(i), path+".")...)
+			continue
+		}
+		settings = append(settings, setting{value: v.Field(i), field: field, path: path})
+	}
+	return settings
+}
+
+// Set a leaf from its text representation
+func setValue(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == reflect.TypeFor[time.Duration]():
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, name string, hash string, scopes []string) (int64, error) {
+	var id int64
+	query := "INSERT INTO api_key (name, key_hash, scopes, created_at) VALUES (?, ?, ?, ?) RETURNING id"
+	err := s.queryRowContext(ctx, query, name, hash, strings.Join(scopes, " "), time.Now().UTC()).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	var scopes string
+	query := "SELECT id, name, key_hash, scopes, created_at, last_used_at, revoked_at FROM api_key WHERE key_hash = ?"
+	err := s.queryRowContext(ctx, query, hash).Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Hash,
+		&scopes,
+		&apiKey.CreatedAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Scopes = strings.Fields(scopes)
+	return &apiKey, nil
+}
+
+func (s *Store) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query := "SELECT id, name, key_hash, scopes, created_at, last_used_at, revoked_at FROM api_key ORDER BY id"
+	rows, err := s.queryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []models.APIKey
+
+	for rows.Next() {
+		var apiKey models.APIKey
+		var scopes string
+		if err := rows.Scan(
+			&apiKey.ID,
+			&apiKey.Name,
+			&apiKey.Hash,
+			&scopes,
+			&apiKey.CreatedAt,
+			&apiKey.LastUsedAt,
+			&apiKey.RevokedAt); err != nil {
+			return apiKeys, err
+		}
+		apiKey.Scopes = strings.Fields(scopes)
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return apiKeys, err
+	}
+	return apiKeys, nil
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	query := "UPDATE api_key SET last_used_at = ? WHERE id = ?"
+	_, err := s.execContext(ctx, query, usedAt.UTC(), id)
+	return err
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, name string) error {
+	query := "UPDATE api_key SET revoked_at = ? WHERE name = ? AND revoked_at IS NULL"
+	result, err := s.execContext(ctx, query, time.Now().UTC(), name)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+	return nil
+}
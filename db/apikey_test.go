@@ -0,0 +1,281 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "successful insert",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					if args[2] != "read write" {
+						t.Errorf("expected joined scopes, got %v", args[2])
+					}
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int64) = 3
+							return nil
+						},
+					}
+				},
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "database insert error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return models.ErrDBRequestFailed
+						},
+					}
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			got, err := store.CreateAPIKey(context.Background(), "batch", "hash", []string{"read", "write"})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Store.CreateAPIKey() got id %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	usedAt := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		want    *models.APIKey
+		wantErr error
+	}{
+		{
+			name: "successful query api key",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int64) = int64(1)
+							*dest[1].(*string) = "batch"
+							*dest[2].(*string) = "hash"
+							*dest[3].(*string) = "read write"
+							*dest[4].(*time.Time) = usedAt
+							*dest[5].(**time.Time) = &usedAt
+							return nil
+						},
+					}
+				},
+			},
+			want: &models.APIKey{
+				ID:         1,
+				Name:       "batch",
+				Hash:       "hash",
+				Scopes:     []string{"read", "write"},
+				CreatedAt:  usedAt,
+				LastUsedAt: &usedAt,
+			},
+		},
+		{
+			name: "no rows found error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return sql.ErrNoRows
+						},
+					}
+				},
+			},
+			want:    nil,
+			wantErr: models.ErrAPIKeyNotFound,
+		},
+		{
+			name: "scan error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return models.ErrScanFailed
+						},
+					}
+				},
+			},
+			want:    nil,
+			wantErr: models.ErrScanFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			got, err := store.GetAPIKeyByHash(context.Background(), "hash")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Store.GetAPIKeyByHash() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	createdAt := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		want    []models.APIKey
+		wantErr bool
+	}{
+		{
+			name: "successful query with one key",
+			mockDB: &MockDB{
+				queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+					callCount := 0
+					return &MockRows{
+						nextFunc: func() bool {
+							callCount++
+							return callCount <= 1
+						},
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int64) = int64(1)
+							*dest[1].(*string) = "batch"
+							*dest[2].(*string) = "hash"
+							*dest[3].(*string) = ""
+							*dest[4].(*time.Time) = createdAt
+							return nil
+						},
+					}, nil
+				},
+			},
+			want: []models.APIKey{
+				{ID: 1, Name: "batch", Hash: "hash", Scopes: []string{}, CreatedAt: createdAt},
+			},
+			wantErr: false,
+		},
+		{
+			name: "database query error",
+			mockDB: &MockDB{
+				queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+					return nil, models.ErrDBRequestFailed
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "DB error",
+			mockDB: &MockDB{
+				queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+					return &MockRows{
+						errFunc: func() error {
+							return models.ErrDBRequestFailed
+						},
+					}, nil
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			got, err := store.GetAPIKeys(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Store.GetAPIKeys() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		wantErr error
+	}{
+		{
+			name: "successful revocation",
+			mockDB: &MockDB{
+				execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+					return MockResult{rowsAffected: 1}, nil
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "unknown or already revoked key",
+			mockDB: &MockDB{
+				execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+					return MockResult{rowsAffected: 0}, nil
+				},
+			},
+			wantErr: models.ErrAPIKeyNotFound,
+		},
+		{
+			name: "database exec error",
+			mockDB: &MockDB{
+				execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+					return nil, models.ErrDBRequestFailed
+				},
+			},
+			wantErr: models.ErrDBRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			err := store.RevokeAPIKey(context.Background(), "batch")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flmailla/resume/models"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Copy a SQLite database into a new file with the online backup API,
+// the copy being consistent while the database is being written.
+// The file is written aside then renamed, a failed backup leaving no file.
+func Backup(ctx context.Context, src *sql.DB, path string) error {
+	if dialectOfDB(src) != SQLite {
+		return models.ErrBackupUnsupported
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := backupInto(ctx, src, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func backupInto(ctx context.Context, src *sql.DB, path string) error {
+	dest, err := sql.Open(SQLite.Driver, path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			backup, err := destDriverConn.(*sqlite3.SQLiteConn).Backup("main", srcDriverConn.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			// Every page in a single step, the copy not restarting
+			// on the writes made meanwhile
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// Backup of a SQLite database in a temporary file,
+// removed when the returned reader is closed
+func Snapshot(ctx context.Context, src *sql.DB) (io.ReadCloser, error) {
+	file, err := os.CreateTemp("", "resume-snapshot-*.db")
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	if err := Backup(ctx, src, file.Name()); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+	snapshot, err := os.Open(file.Name())
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return &snapshotFile{File: snapshot}, nil
+}
+
+type snapshotFile struct {
+	*os.File
+}
+
+func (f *snapshotFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.File.Name()))
+}
+
+// Replace the SQLite file of a data source name by a backup, once checked:
+// the copy put in place must pass the integrity check and hold a schema this
+// binary can migrate. The service must be stopped, a database still open
+// being refused. The replaced file is kept as <file>.pre-restore, its journal
+// checkpointed into it first.
+func Restore(ctx context.Context, backup string, dsn string) error {
+	if DialectOf(dsn) != SQLite || isMemoryDSN(dsn) {
+		return models.ErrBackupUnsupported
+	}
+	target := sqlitePath(dsn)
+
+	// Checked once copied next to the target, what is checked being what is swapped
+	tmp := target + ".restore"
+	if err := copyFile(backup, tmp); err != nil {
+		return fmt.Errorf("failed to copy the backup: %w", err)
+	}
+	defer removeSQLiteFiles(tmp)
+	if err := checkBackup(ctx, tmp); err != nil {
+		return err
+	}
+
+	previous := target + ".pre-restore"
+	removeSQLiteFiles(previous)
+	if _, err := os.Stat(target); err == nil {
+		lock, err := keepSQLite(ctx, target, previous)
+		if err != nil {
+			return err
+		}
+		// Released once the backup is in place, no process opening the
+		// replaced file and writing a journal next to it meanwhile
+		defer lock.Close()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// The journal of the replaced file must not be applied to the backup
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(target + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp, target)
+}
+
+// Keep the database of path as previous, once its journal is checkpointed.
+// Both are done under an exclusive lock, which the connections of a running
+// server prevent, held by the returned connection until it is closed.
+func keepSQLite(ctx context.Context, path string, previous string) (*sql.DB, error) {
+	conn, err := sql.Open(SQLite.Driver, withParams(path, url.Values{
+		"_busy_timeout": {"0"},
+		"_locking_mode": {"EXCLUSIVE"},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+
+	var busy, frames, checkpointed int
+	err = conn.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &frames, &checkpointed)
+	if err == nil && busy == 0 {
+		// Held by the exclusive locking mode until the connection closes
+		_, err = conn.ExecContext(ctx, "BEGIN EXCLUSIVE; COMMIT")
+	}
+	if err == nil && busy == 0 {
+		// The journal deleted now rather than on close, by its path,
+		// when it could be the one of the restored database
+		_, err = conn.ExecContext(ctx, "PRAGMA journal_mode = DELETE")
+	}
+	var sqliteErr sqlite3.Error
+	if busy != 0 || errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+		conn.Close()
+		return nil, models.ErrDatabaseInUse
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock the current database: %w", err)
+	}
+
+	if err := os.Link(path, previous); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to keep the current database: %w", err)
+	}
+	return conn, nil
+}
+
+// Check a backup before it is restored
+func checkBackup(ctx context.Context, path string) error {
+	conn, err := sql.Open(SQLite.Driver, withParams(path, url.Values{"_query_only": {"1"}}))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := IntegrityCheck(ctx, conn); err != nil {
+		return fmt.Errorf("backup rejected: %w", err)
+	}
+	var version int
+	if err := conn.QueryRowContext(ctx, SQLite.getVersion).Scan(&version); err != nil {
+		return fmt.Errorf("backup rejected: %w", err)
+	}
+	if version < 1 || version > SchemaVersion {
+		return fmt.Errorf("backup rejected: %w: got %d, this binary supports 1 to %d", models.ErrSchemaVersion, version, SchemaVersion)
+	}
+	return nil
+}
+
+// File of a SQLite data source name
+func sqlitePath(dsn string) string {
+	path, _, _ := strings.Cut(dsn, "?")
+	return strings.TrimPrefix(path, "file:")
+}
+
+func copyFile(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func removeSQLiteFiles(path string) {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		os.Remove(path + suffix)
+	}
+}
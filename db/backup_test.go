@@ -0,0 +1,272 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Migrated SQLite database in a temporary directory, with its pools
+func openTestSQLite(t *testing.T, path string) (*sql.DB, *sql.DB) {
+	t.Helper()
+	write, read, err := OpenSQLite(path, DefaultSQLiteOptions)
+	if err != nil {
+		t.Fatalf("OpenSQLite() = %v", err)
+	}
+	t.Cleanup(func() { write.Close(); read.Close() })
+	if err := Migrate(write); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return write, read
+}
+
+func TestBackupWhileWriting(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	write, read := openTestSQLite(t, filepath.Join(dir, "resume.db"))
+	store := NewStoreFromPools(write, read, 0)
+
+	stop := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				store.CreateAPIKey(ctx, fmt.Sprintf("key-%d", i), fmt.Sprintf("hash-%d", i), nil)
+			}
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	path := filepath.Join(dir, "backup.db")
+	err := Backup(ctx, read, path)
+	close(stop)
+	writer.Wait()
+	if err != nil {
+		t.Fatalf("Backup() = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the temporary file to be gone, got %v", err)
+	}
+
+	if err := checkBackup(ctx, path); err != nil {
+		t.Fatalf("checkBackup() = %v", err)
+	}
+	backup, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	apiKeys, err := NewStoreFromSQLDB(backup, 0).GetAPIKeys(ctx)
+	if err != nil || len(apiKeys) == 0 {
+		t.Errorf("expected the api keys written before the backup, got %d, %v", len(apiKeys), err)
+	}
+}
+
+func TestBackupUnsupported(t *testing.T) {
+	conn, err := sql.Open("postgres", "postgres://localhost/resume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := Backup(context.Background(), conn, filepath.Join(t.TempDir(), "backup.db")); !errors.Is(err, models.ErrBackupUnsupported) {
+		t.Errorf("Backup() = %v, want %v", err, models.ErrBackupUnsupported)
+	}
+	if err := Restore(context.Background(), "backup.db", "postgres://localhost/resume"); !errors.Is(err, models.ErrBackupUnsupported) {
+		t.Errorf("Restore() = %v, want %v", err, models.ErrBackupUnsupported)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	_, read := openTestSQLite(t, filepath.Join(t.TempDir(), "resume.db"))
+
+	snapshot, err := Snapshot(context.Background(), read)
+	if err != nil {
+		t.Fatalf("Snapshot() = %v", err)
+	}
+	content, err := io.ReadAll(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(content, []byte("SQLite format 3\x00")) {
+		t.Errorf("expected a SQLite file, got %q...", content[:16])
+	}
+
+	name := snapshot.(*snapshotFile).Name()
+	snapshot.Close()
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the snapshot file to be removed, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		backup   func(t *testing.T, path string)
+		wantFail bool
+		wantErr  error
+	}{
+		{
+			name: "Valid backup",
+			backup: func(t *testing.T, path string) {
+				write, read := openTestSQLite(t, path)
+				if _, err := write.Exec("UPDATE profile SET firstname = 'Restored'"); err != nil {
+					t.Fatal(err)
+				}
+				// Closed to checkpoint the journal, as a backup is
+				write.Close()
+				read.Close()
+			},
+		},
+		{
+			name: "Corrupted backup",
+			backup: func(t *testing.T, path string) {
+				os.WriteFile(path, bytes.Repeat([]byte{0xA5}, 8192), 0o600)
+			},
+			wantFail: true,
+		},
+		{
+			name: "Not a resume database",
+			backup: func(t *testing.T, path string) {
+				conn, _ := sql.Open("sqlite3", path)
+				defer conn.Close()
+				conn.Exec("CREATE TABLE other (id INTEGER)")
+			},
+			wantFail: true,
+			wantErr:  models.ErrSchemaVersion,
+		},
+		{
+			name: "Newer schema",
+			backup: func(t *testing.T, path string) {
+				write, read := openTestSQLite(t, path)
+				write.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion+1))
+				write.Close()
+				read.Close()
+			},
+			wantFail: true,
+			wantErr:  models.ErrSchemaVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "resume.db")
+			current, _ := openTestSQLite(t, target)
+			current.Close()
+
+			backup := filepath.Join(t.TempDir(), "backup.db")
+			tt.backup(t, backup)
+
+			err := Restore(ctx, backup, target)
+			if (err != nil) != tt.wantFail || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore() = %v, want failure %v (%v)", err, tt.wantFail, tt.wantErr)
+			}
+
+			restored, err := sql.Open("sqlite3", target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer restored.Close()
+			var firstname string
+			restored.QueryRow("SELECT firstname FROM profile WHERE id = 1").Scan(&firstname)
+
+			want := "Florent"
+			if !tt.wantFail {
+				want = "Restored"
+				if _, err := os.Stat(target + ".pre-restore"); err != nil {
+					t.Errorf("expected the replaced database to be kept, got %v", err)
+				}
+			}
+			if firstname != want {
+				t.Errorf("expected the profile of %s, got %q", want, firstname)
+			}
+			if _, err := os.Stat(target + ".restore"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected the restored copy to be gone, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreKeepsTheJournal(t *testing.T) {
+	ctx := context.Background()
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	write, read := openTestSQLite(t, backup)
+	write.Close()
+	read.Close()
+
+	// Left with pages in its journal, as by a crash
+	live := filepath.Join(t.TempDir(), "resume.db")
+	write, _ = openTestSQLite(t, live)
+	write.Exec("PRAGMA wal_autocheckpoint = 0")
+	if _, err := write.Exec("UPDATE profile SET firstname = 'Journaled'"); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "resume.db")
+	for _, suffix := range []string{"", "-wal"} {
+		if err := copyFile(live+suffix, target+suffix); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Restore(ctx, backup, target); err != nil {
+		t.Fatalf("Restore() = %v", err)
+	}
+	for _, path := range []string{target + "-wal", target + "-shm", target + ".pre-restore-wal"} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected no %s left, got %v", filepath.Base(path), err)
+		}
+	}
+	previous, err := sql.Open("sqlite3", target+".pre-restore?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer previous.Close()
+	var firstname string
+	previous.QueryRow("SELECT firstname FROM profile WHERE id = 1").Scan(&firstname)
+	if firstname != "Journaled" {
+		t.Errorf("expected the journal in the replaced database, got %q", firstname)
+	}
+}
+
+func TestRestoreWhileOpen(t *testing.T) {
+	ctx := context.Background()
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	write, read := openTestSQLite(t, backup)
+	write.Close()
+	read.Close()
+
+	target := filepath.Join(t.TempDir(), "resume.db")
+	write, read = openTestSQLite(t, target)
+	if err := read.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Restore(ctx, backup, target); !errors.Is(err, models.ErrDatabaseInUse) {
+		t.Fatalf("Restore() = %v, want %v", err, models.ErrDatabaseInUse)
+	}
+	if _, err := os.Stat(target + ".pre-restore"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected nothing kept, got %v", err)
+	}
+	var firstname string
+	if err := read.QueryRow("SELECT firstname FROM profile WHERE id = 1").Scan(&firstname); err != nil {
+		t.Errorf("expected the database to be left in place, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+func (s *Store) CreateOAuthClient(ctx context.Context, clientId string, name string, secretHash string, scopes []string) (int64, error) {
+	var id int64
+	query := "INSERT INTO oauth_client (client_id, name, secret_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id"
+	err := s.queryRowContext(ctx, query, clientId, name, secretHash, strings.Join(scopes, " "), time.Now().UTC()).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Store) GetOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	var scopes string
+	query := "SELECT id, client_id, name, secret_hash, scopes, created_at, revoked_at FROM oauth_client WHERE client_id = ?"
+	err := s.queryRowContext(ctx, query, clientId).Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.Name,
+		&client.SecretHash,
+		&scopes,
+		&client.CreatedAt,
+		&client.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrClientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.Scopes = strings.Fields(scopes)
+	return &client, nil
+}
+
+func (s *Store) RevokeOAuthClient(ctx context.Context, clientId string) error {
+	query := "UPDATE oauth_client SET revoked_at = ? WHERE client_id = ? AND revoked_at IS NULL"
+	result, err := s.execContext(ctx, query, time.Now().UTC(), clientId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrClientNotFound
+	}
+	return nil
+}
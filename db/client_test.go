@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestCreateOAuthClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "successful insert",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int64) = 1
+							return nil
+						},
+					}
+				},
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "database insert error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return models.ErrDBRequestFailed
+						},
+					}
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			got, err := store.CreateOAuthClient(context.Background(), "client", "frontend", "hash", []string{"read"})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Store.CreateOAuthClient() got id %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOAuthClientByClientId(t *testing.T) {
+	createdAt := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		want    *models.OAuthClient
+		wantErr error
+	}{
+		{
+			name: "successful query client",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int64) = int64(1)
+							*dest[1].(*string) = "client"
+							*dest[2].(*string) = "frontend"
+							*dest[3].(*string) = "hash"
+							*dest[4].(*string) = "read write"
+							*dest[5].(*time.Time) = createdAt
+							return nil
+						},
+					}
+				},
+			},
+			want: &models.OAuthClient{
+				ID:         1,
+				ClientID:   "client",
+				Name:       "frontend",
+				SecretHash: "hash",
+				Scopes:     []string{"read", "write"},
+				CreatedAt:  createdAt,
+			},
+		},
+		{
+			name: "no rows found error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return sql.ErrNoRows
+						},
+					}
+				},
+			},
+			want:    nil,
+			wantErr: models.ErrClientNotFound,
+		},
+		{
+			name: "scan error",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return models.ErrScanFailed
+						},
+					}
+				},
+			},
+			want:    nil,
+			wantErr: models.ErrScanFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			got, err := store.GetOAuthClientByClientId(context.Background(), "client")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Store.GetOAuthClientByClientId() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeOAuthClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		wantErr error
+	}{
+		{
+			name: "successful revocation",
+			mockDB: &MockDB{
+				execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+					return MockResult{rowsAffected: 1}, nil
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "unknown client",
+			mockDB: &MockDB{
+				execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+					return MockResult{rowsAffected: 0}, nil
+				},
+			},
+			wantErr: models.ErrClientNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			err := store.RevokeOAuthClient(context.Background(), "client")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Variable holding the PostgreSQL instance of the conformance suite
+const postgresTestEnv string = "RESUME_TEST_POSTGRES_DSN"
+
+// Instance tried when the variable is not set
+const postgresTestDSN string = "postgres://postgres@localhost:5432/postgres?sslmode=disable&connect_timeout=1"
+
+func TestConformanceSQLite(t *testing.T) {
+	conn, read, err := OpenSQLite(filepath.Join(t.TempDir(), "resume.db"), DefaultSQLiteOptions)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(); read.Close() })
+
+	runConformance(t, conn, read)
+}
+
+// Run against the PostgreSQL instance of RESUME_TEST_POSTGRES_DSN, or a local
+// one, in a throwaway schema. Skipped when no instance answers.
+func TestConformancePostgres(t *testing.T) {
+	dsn, configured := os.LookupEnv(postgresTestEnv)
+	if !configured {
+		dsn = postgresTestDSN
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err == nil {
+		err = admin.Ping()
+	}
+	if err != nil {
+		if configured {
+			t.Fatalf("failed to reach %s: %v", postgresTestEnv, err)
+		}
+		t.Skipf("no local PostgreSQL instance, set %s to run the suite: %v", postgresTestEnv, err)
+	}
+	defer admin.Close()
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	schema := "resume_test_" + hex.EncodeToString(suffix)
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("failed to create the test schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	conn, err := sql.Open("postgres", dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	runConformance(t, conn, nil)
+}
+
+// Behaviour every dialect must have, on a database migrated from scratch,
+// reads going to the read pool when there is one
+func runConformance(t *testing.T, conn *sql.DB, read *sql.DB) {
+	ctx := context.Background()
+
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	// Migrating and seeding twice is harmless
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+
+	store := NewStoreFromPools(conn, read, 5*time.Second)
+
+	t.Run("schema", func(t *testing.T) {
+		if err := store.Ping(ctx); err != nil {
+			t.Errorf("Ping() = %v", err)
+		}
+		if err := store.CheckSchemaVersion(ctx); err != nil {
+			t.Errorf("CheckSchemaVersion() = %v", err)
+		}
+	})
+
+	runContract(t, store)
+
+	t.Run("savepoints", func(t *testing.T) {
+		id, err := store.CreateExperience(ctx, 1, models.Experience{Title: "Savepoints", StartDate: time.Now()})
+		if err != nil {
+			t.Fatalf("CreateExperience() = %v", err)
+		}
+
+		errDiscarded := errors.New("discarded")
+		err = store.WithTx(ctx, func(tx *Store) error {
+			if err := tx.LinkSkillToExperience(ctx, id, 3); err != nil {
+				return err
+			}
+			err := tx.WithTx(ctx, func(nested *Store) error {
+				nested.LinkSkillToExperience(ctx, id, 4)
+				return errDiscarded
+			})
+			if !errors.Is(err, errDiscarded) {
+				return err
+			}
+			return tx.LinkSkillToExperience(ctx, id, 5)
+		})
+		if err != nil {
+			t.Fatalf("WithTx() = %v", err)
+		}
+
+		store.WithTx(ctx, func(tx *Store) error {
+			tx.LinkSkillToExperience(ctx, id, 6)
+			return errDiscarded
+		})
+
+		skills, err := store.GetDistinctSkillsByExperience(ctx, int(id))
+		if ids := skillIds(skills); err != nil || !reflect.DeepEqual(ids, []int64{3, 5}) {
+			t.Errorf("expected the skills 3 and 5 to be linked, got %v, %v", ids, err)
+		}
+	})
+}
+
+// Behaviour every Storage must have, starting from the seeded resume
+func runContract(t *testing.T, store Storage) {
+	ctx := context.Background()
+
+	t.Run("seeded resume", func(t *testing.T) {
+		profile, err := store.GetProfileById(ctx, 1)
+		if err != nil || profile.FirstName != "Florent" {
+			t.Errorf("GetProfileById() = %+v, %v", profile, err)
+		}
+
+		if _, err := store.GetProfileById(ctx, 404); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetProfileById() = %v, want %v", err, sql.ErrNoRows)
+		}
+
+		experiences, err := store.GetDistinctExperiencesByProfile(ctx, 1)
+		if err != nil || len(experiences) != 6 {
+			t.Errorf("GetDistinctExperiencesByProfile() = %d experiences, %v", len(experiences), err)
+		}
+
+		licences, err := store.GetDistinctLicencesByProfile(ctx, 1)
+		if err != nil || len(licences) != 8 {
+			t.Errorf("GetDistinctLicencesByProfile() = %d licences, %v", len(licences), err)
+		}
+
+		educations, err := store.GetDistinctEducationsByProfile(ctx, 1)
+		if err != nil || len(educations) != 1 {
+			t.Errorf("GetDistinctEducationsByProfile() = %d educations, %v", len(educations), err)
+		}
+
+		skills, err := store.GetDistinctSkills(ctx)
+		if err != nil || len(skills) != 26 {
+			t.Errorf("GetDistinctSkills() = %d skills, %v", len(skills), err)
+		}
+
+		skills, err = store.GetDistinctSkillsByProfile(ctx, 1)
+		if err != nil || len(skills) != 26 {
+			t.Errorf("GetDistinctSkillsByProfile() = %d skills, %v", len(skills), err)
+		}
+
+		skills, err = store.GetDistinctSkillsByExperience(ctx, 5)
+		if err != nil || len(skills) != 8 {
+			t.Errorf("GetDistinctSkillsByExperience() = %d skills, %v", len(skills), err)
+		}
+
+		experiences, err = store.GetDistinctExperiencesByProfile(ctx, 404)
+		if err != nil || experiences != nil {
+			t.Errorf("GetDistinctExperiencesByProfile() = %+v, %v, want nothing", experiences, err)
+		}
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		id, err := store.CreateAPIKey(ctx, "batch", "conformance-hash", []string{"read", "write"})
+		if err != nil || id == 0 {
+			t.Fatalf("CreateAPIKey() = %d, %v", id, err)
+		}
+		if _, err := store.CreateAPIKey(ctx, "batch", "other-hash", nil); err == nil {
+			t.Error("CreateAPIKey() with a used name, expected an error")
+		}
+
+		if err := store.TouchAPIKey(ctx, id, time.Now()); err != nil {
+			t.Errorf("TouchAPIKey() = %v", err)
+		}
+
+		apiKey, err := store.GetAPIKeyByHash(ctx, "conformance-hash")
+		if err != nil {
+			t.Fatalf("GetAPIKeyByHash() = %v", err)
+		}
+		if apiKey.ID != id || !reflect.DeepEqual(apiKey.Scopes, []string{"read", "write"}) || apiKey.LastUsedAt == nil {
+			t.Errorf("GetAPIKeyByHash() = %+v", apiKey)
+		}
+
+		if err := store.RevokeAPIKey(ctx, "batch"); err != nil {
+			t.Errorf("RevokeAPIKey() = %v", err)
+		}
+		if err := store.RevokeAPIKey(ctx, "batch"); !errors.Is(err, models.ErrAPIKeyNotFound) {
+			t.Errorf("RevokeAPIKey() twice = %v, want %v", err, models.ErrAPIKeyNotFound)
+		}
+
+		apiKeys, err := store.GetAPIKeys(ctx)
+		if err != nil || len(apiKeys) != 1 || apiKeys[0].RevokedAt == nil {
+			t.Errorf("GetAPIKeys() = %+v, %v", apiKeys, err)
+		}
+
+		if _, err := store.GetAPIKeyByHash(ctx, "unknown"); !errors.Is(err, models.ErrAPIKeyNotFound) {
+			t.Errorf("GetAPIKeyByHash() = %v, want %v", err, models.ErrAPIKeyNotFound)
+		}
+	})
+
+	t.Run("oauth clients", func(t *testing.T) {
+		id, err := store.CreateOAuthClient(ctx, "conformance", "frontend", "hash", []string{"read"})
+		if err != nil || id == 0 {
+			t.Fatalf("CreateOAuthClient() = %d, %v", id, err)
+		}
+		if _, err := store.CreateOAuthClient(ctx, "conformance", "other", "hash", nil); err == nil {
+			t.Error("CreateOAuthClient() with a used client id, expected an error")
+		}
+
+		client, err := store.GetOAuthClientByClientId(ctx, "conformance")
+		if err != nil || client.ID != id || client.Name != "frontend" || !reflect.DeepEqual(client.Scopes, []string{"read"}) {
+			t.Errorf("GetOAuthClientByClientId() = %+v, %v", client, err)
+		}
+
+		if err := store.RevokeOAuthClient(ctx, "conformance"); err != nil {
+			t.Errorf("RevokeOAuthClient() = %v", err)
+		}
+		if err := store.RevokeOAuthClient(ctx, "conformance"); !errors.Is(err, models.ErrClientNotFound) {
+			t.Errorf("RevokeOAuthClient() twice = %v, want %v", err, models.ErrClientNotFound)
+		}
+		if _, err := store.GetOAuthClientByClientId(ctx, "unknown"); !errors.Is(err, models.ErrClientNotFound) {
+			t.Errorf("GetOAuthClientByClientId() = %v, want %v", err, models.ErrClientNotFound)
+		}
+	})
+
+	t.Run("experiences", func(t *testing.T) {
+		experience := models.Experience{
+			Title:     "Conformance",
+			Company:   "Resume",
+			Location:  "Lausanne",
+			StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Skills:    []models.Skill{{ID: 1}, {ID: 2}},
+		}
+		id, err := store.CreateExperience(ctx, 1, experience)
+		if err != nil || id <= 6 {
+			t.Fatalf("CreateExperience() = %d, %v, want an id past the seeded ones", id, err)
+		}
+
+		skills, err := store.GetDistinctSkillsByExperience(ctx, int(id))
+		if ids := skillIds(skills); err != nil || !reflect.DeepEqual(ids, []int64{1, 2}) {
+			t.Errorf("GetDistinctSkillsByExperience() = %v, %v", ids, err)
+		}
+
+		if err := store.LinkSkillToExperience(ctx, id, 3); err != nil {
+			t.Errorf("LinkSkillToExperience() = %v", err)
+		}
+		if err := store.LinkSkillToExperience(ctx, id, 3); err == nil {
+			t.Error("LinkSkillToExperience() twice, expected an error")
+		}
+
+		if err := store.LinkSkillToExperience(ctx, id, 404); err == nil {
+			t.Error("LinkSkillToExperience() to an unknown skill, expected an error")
+		}
+
+		// A failing link leaves nothing behind
+		experience.Skills = []models.Skill{{ID: 4}, {ID: 4}}
+		if _, err := store.CreateExperience(ctx, 1, experience); err == nil {
+			t.Error("CreateExperience() linking a skill twice, expected an error")
+		}
+		experience.Skills = []models.Skill{{ID: 4}, {ID: 404}}
+		if _, err := store.CreateExperience(ctx, 1, experience); err == nil {
+			t.Error("CreateExperience() linking an unknown skill, expected an error")
+		}
+		experiences, err := store.GetDistinctExperiencesByProfile(ctx, 1)
+		if err != nil || len(experiences) != 7 {
+			t.Errorf("GetDistinctExperiencesByProfile() = %d experiences, %v, want 7", len(experiences), err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err := store.GetProfileById(cancelled, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("GetProfileById() = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+// Ids of skills, sorted as the SQL dialects do not order the joins alike
+func skillIds(skills []models.Skill) []int64 {
+	var ids []int64
+	for _, skill := range skills {
+		ids = append(ids, skill.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 )
 
@@ -8,17 +10,24 @@ import (
 type MockDB struct {
 	queryFunc    func(query string, args ...interface{}) (RowsInterface, error)
 	queryRowFunc func(query string, args ...interface{}) RowInterface
+	execFunc     func(query string, args ...interface{}) (sql.Result, error)
+	beginFunc    func() (TxInterface, error)
+	prepareFunc  func(query string) error
 	close        func() error
+	ctx          context.Context // context of the last statement
+	stmts        []*MockStmt     // prepared so far
 }
 
-func (m *MockDB) Query(query string, args ...interface{}) (RowsInterface, error) {
+func (m *MockDB) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	m.ctx = ctx
 	if m.queryFunc != nil {
 		return m.queryFunc(query, args...)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockDB) QueryRow(query string, args ...interface{}) RowInterface {
+func (m *MockDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	m.ctx = ctx
 	if m.queryRowFunc != nil {
 		return m.queryRowFunc(query, args...)
 	}
@@ -27,10 +36,89 @@ func (m *MockDB) QueryRow(query string, args ...interface{}) RowInterface {
 	}}
 }
 
+func (m *MockDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.ctx = ctx
+	if m.execFunc != nil {
+		return m.execFunc(query, args...)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (TxInterface, error) {
+	m.ctx = ctx
+	if m.beginFunc != nil {
+		return m.beginFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDB) PrepareContext(ctx context.Context, query string) (StmtInterface, error) {
+	m.ctx = ctx
+	if m.prepareFunc != nil {
+		if err := m.prepareFunc(query); err != nil {
+			return nil, err
+		}
+	}
+	stmt := &MockStmt{db: m, query: query}
+	m.stmts = append(m.stmts, stmt)
+	return stmt, nil
+}
+
 func (m *MockDB) Close() error {
 	return m.close()
 }
 
+// Statement running its query on the mock database
+type MockStmt struct {
+	db     *MockDB
+	query  string
+	closed bool
+}
+
+func (m *MockStmt) QueryContext(ctx context.Context, args ...interface{}) (RowsInterface, error) {
+	return m.db.QueryContext(ctx, m.query, args...)
+}
+
+func (m *MockStmt) QueryRowContext(ctx context.Context, args ...interface{}) RowInterface {
+	return m.db.QueryRowContext(ctx, m.query, args...)
+}
+
+func (m *MockStmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	return m.db.ExecContext(ctx, m.query, args...)
+}
+
+func (m *MockStmt) Close() error {
+	m.closed = true
+	return nil
+}
+
+// Transaction recording its statements and outcome
+type MockTx struct {
+	MockDB
+	commitErr  error
+	statements []string
+	committed  bool
+	rolledBack bool
+}
+
+func (m *MockTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.statements = append(m.statements, query)
+	if m.execFunc == nil {
+		return MockResult{}, nil
+	}
+	return m.MockDB.ExecContext(ctx, query, args...)
+}
+
+func (m *MockTx) Commit() error {
+	m.committed = true
+	return m.commitErr
+}
+
+func (m *MockTx) Rollback() error {
+	m.rolledBack = true
+	return nil
+}
+
 type MockRows struct {
 	nextFunc  func() bool
 	scanFunc  func(dest ...interface{}) error
@@ -78,3 +166,16 @@ func (m *MockRow) Scan(dest ...interface{}) error {
 	}
 	return nil
 }
+
+type MockResult struct {
+	lastInsertId int64
+	rowsAffected int64
+}
+
+func (m MockResult) LastInsertId() (int64, error) {
+	return m.lastInsertId, nil
+}
+
+func (m MockResult) RowsAffected() (int64, error) {
+	return m.rowsAffected, nil
+}
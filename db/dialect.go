@@ -0,0 +1,94 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQL differences between the supported databases
+type Dialect struct {
+	Driver     string // database/sql driver name
+	System     string // OpenTelemetry db.system.name
+	migrations []string
+	// Statements reading and writing the schema version,
+	// after the bootstrap statement ensured they can run
+	bootstrap  string
+	getVersion string
+	setVersion string // format taking the version
+	afterSeed  string
+	// Placeholders rewriting, the queries being written with ?
+	rebind func(query string) string
+}
+
+var SQLite = &Dialect{
+	Driver:     "sqlite3",
+	System:     "sqlite",
+	migrations: sqliteMigrations,
+	getVersion: "PRAGMA user_version",
+	setVersion: "PRAGMA user_version = %d",
+	rebind:     func(query string) string { return query },
+}
+
+var Postgres = &Dialect{
+	Driver:     "postgres",
+	System:     "postgresql",
+	migrations: postgresMigrations,
+	bootstrap:  "CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)",
+	getVersion: "SELECT COALESCE(MAX(version), 0) FROM schema_version",
+	setVersion: "DELETE FROM schema_version; INSERT INTO schema_version (version) VALUES (%d)",
+	afterSeed:  postgresAfterSeed,
+	rebind:     numberedPlaceholders,
+}
+
+// Dialect of a data source name: postgres:// and postgresql:// URLs
+// select PostgreSQL, anything else being a SQLite file
+func DialectOf(dsn string) *Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return Postgres
+	}
+	return SQLite
+}
+
+// Dialect of an opened database, from its driver
+func dialectOfDB(db *sql.DB) *Dialect {
+	switch db.Driver().(type) {
+	case *pq.Driver:
+		return Postgres
+	case *sqlite3.SQLiteDriver:
+		return SQLite
+	}
+	panic(fmt.Sprintf("db: unsupported driver %T", db.Driver()))
+}
+
+// Rewrite the ? placeholders into $1, $2..., string literals,
+// quoted identifiers and comments being left untouched
+func numberedPlaceholders(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote || (quote == '\n' && c == '\n') {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			quote = '\n'
+		case c == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
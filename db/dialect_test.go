@@ -0,0 +1,100 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestDialectOf(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want *Dialect
+	}{
+		{"./resume.db", SQLite},
+		{"file:resume.db?cache=shared", SQLite},
+		{"postgres://resume@localhost/resume", Postgres},
+		{"postgresql://resume@localhost/resume", Postgres},
+	}
+
+	for _, tt := range tests {
+		if got := DialectOf(tt.dsn); got != tt.want {
+			t.Errorf("DialectOf(%q) = %s, want %s", tt.dsn, got.System, tt.want.System)
+		}
+	}
+}
+
+func TestNumberedPlaceholders(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT id FROM api_key WHERE key_hash = ?",
+			want:  "SELECT id FROM api_key WHERE key_hash = $1",
+		},
+		{
+			query: "INSERT INTO skill_experience (experience_id, skill_id) VALUES (?, ?)",
+			want:  "INSERT INTO skill_experience (experience_id, skill_id) VALUES ($1, $2)",
+		},
+		{
+			query: `SELECT '?', 'it''s ?', "col?" FROM t -- why?
+				WHERE a = ?`,
+			want: `SELECT '?', 'it''s ?', "col?" FROM t -- why?
+				WHERE a = $1`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := numberedPlaceholders(tt.query); got != tt.want {
+			t.Errorf("numberedPlaceholders(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateFromPreviousVersion(t *testing.T) {
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer conn.Close()
+
+	// Database created by a binary at version 1
+	if _, err := conn.Exec(sqliteMigrations[0] + `
+		INSERT INTO licence (id, title, issuer, expires, issued_at, profile_id)
+		VALUES (42, 'CKA', 'CNCF', '2027-01-01 00:00:00', '2024-01-01 00:00:00', 1);
+		PRAGMA user_version = 1;`); err != nil {
+		t.Fatalf("failed to create the version 1 database: %v", err)
+	}
+
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	var version int
+	var licenceType sql.NullString
+	var expires sql.NullTime
+	conn.QueryRow("PRAGMA user_version").Scan(&version)
+	if err := conn.QueryRow("SELECT licence_type, expires FROM licence WHERE id = 42").Scan(&licenceType, &expires); err != nil {
+		t.Fatalf("expected the licence to be kept: %v", err)
+	}
+	if version != SchemaVersion || licenceType.Valid || expires.Time.Year() != 2027 {
+		t.Errorf("unexpected migrated state: version %d, type %v, expires %v", version, licenceType, expires)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer conn.Close()
+	conn.Exec("PRAGMA user_version = 99")
+
+	if err := Migrate(conn); !errors.Is(err, models.ErrSchemaVersion) {
+		t.Errorf("Migrate() = %v, want %v", err, models.ErrSchemaVersion)
+	}
+}
@@ -1,14 +1,17 @@
 package db
 
 import (
+	"context"
+
 	"github.com/flmailla/resume/models"
 )
 
-func (s *Store) GetDistinctEducationsByProfile(profileId int) ([]models.Education, error) {
+func (s *Store) GetDistinctEducationsByProfile(ctx context.Context, profileId int) ([]models.Education, error) {
 	query := `SELECT DISTINCT e.id, e.title, e.issued_at, e.description
 				FROM education as e
-				Where e.profile_id = ?`
-	rows, err := s.db.Query(query, profileId)
+				Where e.profile_id = ?
+				ORDER BY e.id`
+	rows, err := s.queryContext(ctx, query, profileId)
 	if err != nil {
 		return nil, err
 	}
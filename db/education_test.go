@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -100,7 +101,7 @@ func TestGetEducationsByProfile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctEducationsByProfile(1)
+			got, err := store.GetDistinctEducationsByProfile(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Store.GetUsers() error = %v, wantErr %v", err, tt.wantErr)
@@ -1,14 +1,19 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"time"
+
 	"github.com/flmailla/resume/models"
 )
 
-func (s *Store) GetDistinctExperiencesByProfile(profileId int) ([]models.Experience, error) {
+func (s *Store) GetDistinctExperiencesByProfile(ctx context.Context, profileId int) ([]models.Experience, error) {
 	query := `SELECT DISTINCT e.id, e.title, e.company, e.start_date, e.end_date, e.location, e.description
 				FROM experience as e
-				Where e.profile_id = ?`
-	rows, err := s.db.Query(query, profileId)
+				Where e.profile_id = ?
+				ORDER BY e.id`
+	rows, err := s.queryContext(ctx, query, profileId)
 	if err != nil {
 		return nil, err
 	}
@@ -18,15 +23,17 @@ func (s *Store) GetDistinctExperiencesByProfile(profileId int) ([]models.Experie
 
 	for rows.Next() {
 		var experience models.Experience
+		var endDate sql.NullTime
 		if err := rows.Scan(&experience.ID,
 			&experience.Title,
 			&experience.Company,
 			&experience.StartDate,
-			&experience.EndDate,
+			&endDate,
 			&experience.Location,
 			&experience.Description); err != nil {
 			return experiences, err
 		}
+		experience.EndDate = endDate.Time
 		experiences = append(experiences, experience)
 	}
 
@@ -35,3 +42,46 @@ func (s *Store) GetDistinctExperiencesByProfile(profileId int) ([]models.Experie
 	}
 	return experiences, nil
 }
+
+// Insert an experience of a profile along with the links to its skills
+// in a single transaction
+func (s *Store) CreateExperience(ctx context.Context, profileId int, experience models.Experience) (int64, error) {
+	var id int64
+	err := s.WithTx(ctx, func(tx *Store) error {
+		var endDate *time.Time
+		if !experience.EndDate.IsZero() {
+			endDate = &experience.EndDate
+		}
+
+		query := `INSERT INTO experience (title, company, location, description, start_date, end_date, profile_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+		err := tx.db.QueryRowContext(ctx, query,
+			experience.Title,
+			experience.Company,
+			experience.Location,
+			experience.Description,
+			experience.StartDate,
+			endDate,
+			profileId).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		for _, skill := range experience.Skills {
+			if err := tx.LinkSkillToExperience(ctx, id, skill.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Store) LinkSkillToExperience(ctx context.Context, experienceId int64, skillId int64) error {
+	query := "INSERT INTO skill_experience (experience_id, skill_id) VALUES (?, ?)"
+	_, err := s.execContext(ctx, query, experienceId, skillId)
+	return err
+}
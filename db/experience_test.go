@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -31,7 +33,7 @@ func TestGetExperiencesByProfile(t *testing.T) {
 								*dest[1].(*string) = "Job1"
 								*dest[2].(*string) = "Company1"
 								*dest[3].(*time.Time) = time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[4].(*time.Time) = time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
+								dest[4].(sql.Scanner).Scan(time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC))
 								*dest[5].(*string) = "Lausanne"
 								*dest[6].(*string) = "Just a job"
 							} else if callCount == 2 {
@@ -39,7 +41,7 @@ func TestGetExperiencesByProfile(t *testing.T) {
 								*dest[1].(*string) = "Job2"
 								*dest[2].(*string) = "Company2"
 								*dest[3].(*time.Time) = time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[4].(*time.Time) = time.Time{}
+								dest[4].(sql.Scanner).Scan(nil)
 								*dest[5].(*string) = "Sion"
 								*dest[6].(*string) = "Just another job"
 							}
@@ -123,7 +125,7 @@ func TestGetExperiencesByProfile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctExperiencesByProfile(1)
+			got, err := store.GetDistinctExperiencesByProfile(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Check that the database answers
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	if err := s.queryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrDBRequestFailed, err)
+	}
+	return nil
+}
+
+// Version of the schema of the opened database
+func (s *Store) GetSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.queryRowContext(ctx, s.dialect.getVersion).Scan(&version); err != nil {
+		return 0, fmt.Errorf("%w: %w", models.ErrDBRequestFailed, err)
+	}
+	return version, nil
+}
+
+// Check that the database schema is the one this binary expects
+func (s *Store) CheckSchemaVersion(ctx context.Context) error {
+	version, err := s.GetSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("%w: expected %d, got %d", models.ErrSchemaVersion, SchemaVersion, version)
+	}
+	return nil
+}
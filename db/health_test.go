@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		wantErr error
+	}{
+		{
+			name: "database answers",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							*dest[0].(*int) = 1
+							return nil
+						},
+					}
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "database unreachable",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return errors.New("database is locked")
+						},
+					}
+				},
+			},
+			wantErr: models.ErrDBRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			err := store.Ping(context.Background())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	versionRow := func(version int) *MockDB {
+		return &MockDB{
+			queryRowFunc: func(query string, args ...interface{}) RowInterface {
+				return &MockRow{
+					scanFunc: func(dest ...interface{}) error {
+						*dest[0].(*int) = version
+						return nil
+					},
+				}
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mockDB  *MockDB
+		wantErr error
+	}{
+		{
+			name:    "expected version",
+			mockDB:  versionRow(SchemaVersion),
+			wantErr: nil,
+		},
+		{
+			name:    "schema not migrated",
+			mockDB:  versionRow(0),
+			wantErr: models.ErrSchemaVersion,
+		},
+		{
+			name: "query failure",
+			mockDB: &MockDB{
+				queryRowFunc: func(query string, args ...interface{}) RowInterface {
+					return &MockRow{
+						scanFunc: func(dest ...interface{}) error {
+							return errors.New("disk I/O error")
+						},
+					}
+				},
+			},
+			wantErr: models.ErrDBRequestFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.mockDB)
+
+			err := store.CheckSchemaVersion(context.Background())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,10 +1,79 @@
 // db/interfaces.go
 package db
 
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Storage abstracts the resume storage, implemented by the SQL Store
+// and the MemoryStore
+type Storage interface {
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (int, error)
+	CheckSchemaVersion(ctx context.Context) error
+
+	GetProfileById(ctx context.Context, profileId int) (*models.Profile, error)
+	GetProfiles(ctx context.Context) ([]models.Profile, error)
+	GetDistinctEducationsByProfile(ctx context.Context, profileId int) ([]models.Education, error)
+	GetDistinctExperiencesByProfile(ctx context.Context, profileId int) ([]models.Experience, error)
+	CreateExperience(ctx context.Context, profileId int, experience models.Experience) (int64, error)
+	LinkSkillToExperience(ctx context.Context, experienceId int64, skillId int64) error
+	GetDistinctLicencesByProfile(ctx context.Context, profileId int) ([]models.Licence, error)
+	GetDistinctSkills(ctx context.Context) ([]models.Skill, error)
+	GetDistinctSkillsByProfile(ctx context.Context, profileId int) ([]models.Skill, error)
+	GetDistinctSkillsByExperience(ctx context.Context, experienceId int) ([]models.Skill, error)
+
+	CreateAPIKey(ctx context.Context, name string, hash string, scopes []string) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+	RevokeAPIKey(ctx context.Context, name string) error
+
+	CreateOAuthClient(ctx context.Context, clientId string, name string, secretHash string, scopes []string) (int64, error)
+	GetOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error)
+	RevokeOAuthClient(ctx context.Context, clientId string) error
+
+	// Release what the storage holds, the database pools being closed by CloseDB
+	Close() error
+}
+
+var (
+	_ Storage = (*Store)(nil)
+	_ Storage = (*MemoryStore)(nil)
+)
+
+// Querier abstracts the statements shared by sql.DB and sql.Tx
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // DBInterface abstracts sql.DB operations
 type DBInterface interface {
-	Query(query string, args ...interface{}) (RowsInterface, error)
-	QueryRow(query string, args ...interface{}) RowInterface
+	Querier
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (TxInterface, error)
+	PrepareContext(ctx context.Context, query string) (StmtInterface, error)
+}
+
+// TxInterface abstracts sql.Tx operations
+type TxInterface interface {
+	Querier
+	Commit() error
+	Rollback() error
+}
+
+// StmtInterface abstracts sql.Stmt operations, a statement prepared once
+// then run with the arguments of every call
+type StmtInterface interface {
+	QueryContext(ctx context.Context, args ...interface{}) (RowsInterface, error)
+	QueryRowContext(ctx context.Context, args ...interface{}) RowInterface
+	ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error)
+	Close() error
 }
 
 // RowsInterface abstracts sql.Rows operations
@@ -1,14 +1,18 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+
 	"github.com/flmailla/resume/models"
 )
 
-func (s *Store) GetDistinctLicencesByProfile(profileId int) ([]models.Licence, error) {
+func (s *Store) GetDistinctLicencesByProfile(ctx context.Context, profileId int) ([]models.Licence, error) {
 	query := `SELECT DISTINCT l.id, l.title, l.issuer, l.issued_at, l.expires, l.licence_type
 				FROM licence as l
-				Where l.profile_id = ?`
-	rows, err := s.db.Query(query, profileId)
+				Where l.profile_id = ?
+				ORDER BY l.id`
+	rows, err := s.queryContext(ctx, query, profileId)
 	if err != nil {
 		return nil, err
 	}
@@ -18,14 +22,18 @@ func (s *Store) GetDistinctLicencesByProfile(profileId int) ([]models.Licence, e
 
 	for rows.Next() {
 		var licence models.Licence
+		var expires sql.NullTime
+		var licenceType sql.NullString
 		if err := rows.Scan(&licence.ID,
 			&licence.Title,
 			&licence.Issuer,
 			&licence.IssuedAt,
-			&licence.Expires,
-			&licence.LicenceType); err != nil {
+			&expires,
+			&licenceType); err != nil {
 			return licences, err
 		}
+		licence.Expires = expires.Time
+		licence.LicenceType = models.LicenceType(licenceType.String)
 		licences = append(licences, licence)
 	}
 
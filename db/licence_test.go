@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -31,22 +33,22 @@ func TestGetLicencesByProfile(t *testing.T) {
 								*dest[1].(*string) = "Job1"
 								*dest[2].(*string) = "Company1"
 								*dest[3].(*time.Time) = time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[4].(*time.Time) = time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[5].(*models.LicenceType) = models.LICENCE
+								dest[4].(sql.Scanner).Scan(time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC))
+								dest[5].(sql.Scanner).Scan(string(models.LICENCE))
 							} else if callCount == 2 {
 								*dest[0].(*int64) = int64(2)
 								*dest[1].(*string) = "Job2"
 								*dest[2].(*string) = "Company2"
 								*dest[3].(*time.Time) = time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[4].(*time.Time) = time.Time{}
-								*dest[5].(*models.LicenceType) = models.CERTIFICATION
+								dest[4].(sql.Scanner).Scan(nil)
+								dest[5].(sql.Scanner).Scan(string(models.CERTIFICATION))
 							} else if callCount == 3 {
 								*dest[0].(*int64) = int64(3)
 								*dest[1].(*string) = "Job3"
 								*dest[2].(*string) = "Company3"
 								*dest[3].(*time.Time) = time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
-								*dest[4].(*time.Time) = time.Time{}
-								*dest[5].(*models.LicenceType) = ""
+								dest[4].(sql.Scanner).Scan(nil)
+								dest[5].(sql.Scanner).Scan(nil)
 							}
 							return nil
 						},
@@ -134,7 +136,7 @@ func TestGetLicencesByProfile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctLicencesByProfile(1)
+			got, err := store.GetDistinctLicencesByProfile(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,454 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Errors of a write breaking a constraint of the SQL schema
+var (
+	errUniqueConstraint     = errors.New("UNIQUE constraint failed")
+	errForeignKeyConstraint = errors.New("FOREIGN KEY constraint failed")
+)
+
+// Storage kept in memory, for tests and demos. It starts with the static
+// content of the resume and behaves as the SQL Store on a migrated database:
+// same errors, same ordering by id, foreign keys enforced.
+// Nothing is persisted.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	profiles    []models.Profile
+	educations  []memoryEducation
+	experiences []memoryExperience
+	skills      []models.Skill
+	links       []memoryLink
+	licences    []memoryLicence
+	apiKeys     []models.APIKey
+	clients     []models.OAuthClient
+}
+
+type memoryEducation struct {
+	models.Education
+	profileId int64
+}
+
+type memoryExperience struct {
+	models.Experience
+	profileId int64
+}
+
+type memoryLicence struct {
+	models.Licence
+	profileId int64
+}
+
+type memoryLink struct {
+	experienceId int64
+	skillId      int64
+}
+
+// Create a MemoryStore holding the seeded resume
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		profiles:    slices.Clone(seedProfiles),
+		educations:  slices.Clone(seedEducations),
+		experiences: slices.Clone(seedExperiences),
+		skills:      slices.Clone(seedSkills),
+		licences:    slices.Clone(seedLicences),
+	}
+	for _, experience := range seedExperiences {
+		for _, skillId := range seedSkillLinks[experience.ID] {
+			s.links = append(s.links, memoryLink{experienceId: experience.ID, skillId: skillId})
+		}
+	}
+	return s
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// The memory store always has the schema of this binary
+func (s *MemoryStore) GetSchemaVersion(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return SchemaVersion, nil
+}
+
+func (s *MemoryStore) CheckSchemaVersion(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Nothing to release, the content going with the store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) GetProfileById(ctx context.Context, profileId int) (*models.Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, profile := range s.profiles {
+		if profile.ID == int64(profileId) {
+			return &profile, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *MemoryStore) GetProfiles(ctx context.Context) ([]models.Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.profiles), nil
+}
+
+func (s *MemoryStore) GetDistinctEducationsByProfile(ctx context.Context, profileId int) ([]models.Education, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var educations []models.Education
+	for _, education := range s.educations {
+		if education.profileId == int64(profileId) {
+			educations = append(educations, education.Education)
+		}
+	}
+	return educations, nil
+}
+
+func (s *MemoryStore) GetDistinctExperiencesByProfile(ctx context.Context, profileId int) ([]models.Experience, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var experiences []models.Experience
+	for _, experience := range s.experiences {
+		if experience.profileId == int64(profileId) {
+			experiences = append(experiences, experience.Experience)
+		}
+	}
+	return experiences, nil
+}
+
+// Insert an experience of a profile along with the links to its skills,
+// nothing being kept when a link fails
+func (s *MemoryStore) CreateExperience(ctx context.Context, profileId int, experience models.Experience) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.ContainsFunc(s.profiles, func(profile models.Profile) bool { return profile.ID == int64(profileId) }) {
+		return 0, errForeignKeyConstraint
+	}
+
+	id := int64(1)
+	if len(s.experiences) > 0 {
+		id = s.experiences[len(s.experiences)-1].ID + 1
+	}
+
+	var links []memoryLink
+	for _, skill := range experience.Skills {
+		link := memoryLink{experienceId: id, skillId: skill.ID}
+		if slices.Contains(links, link) {
+			return 0, fmt.Errorf("%w: skill_experience.experience_id, skill_experience.skill_id", errUniqueConstraint)
+		}
+		if !s.hasSkill(skill.ID) {
+			return 0, errForeignKeyConstraint
+		}
+		links = append(links, link)
+	}
+
+	experience.ID = id
+	experience.Skills = nil
+	experience.Profile = models.Profile{}
+	s.experiences = append(s.experiences, memoryExperience{Experience: experience, profileId: int64(profileId)})
+	s.links = append(s.links, links...)
+	return id, nil
+}
+
+func (s *MemoryStore) LinkSkillToExperience(ctx context.Context, experienceId int64, skillId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := memoryLink{experienceId: experienceId, skillId: skillId}
+	if slices.Contains(s.links, link) {
+		return fmt.Errorf("%w: skill_experience.experience_id, skill_experience.skill_id", errUniqueConstraint)
+	}
+	if !s.hasExperience(experienceId) || !s.hasSkill(skillId) {
+		return errForeignKeyConstraint
+	}
+	s.links = append(s.links, link)
+	return nil
+}
+
+func (s *MemoryStore) hasExperience(id int64) bool {
+	return slices.ContainsFunc(s.experiences, func(experience memoryExperience) bool { return experience.ID == id })
+}
+
+func (s *MemoryStore) hasSkill(id int64) bool {
+	return slices.ContainsFunc(s.skills, func(skill models.Skill) bool { return skill.ID == id })
+}
+
+func (s *MemoryStore) GetDistinctLicencesByProfile(ctx context.Context, profileId int) ([]models.Licence, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var licences []models.Licence
+	for _, licence := range s.licences {
+		if licence.profileId == int64(profileId) {
+			licences = append(licences, licence.Licence)
+		}
+	}
+	return licences, nil
+}
+
+func (s *MemoryStore) GetDistinctSkills(ctx context.Context) ([]models.Skill, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.skills), nil
+}
+
+func (s *MemoryStore) GetDistinctSkillsByProfile(ctx context.Context, profileId int) ([]models.Skill, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.linkedSkills(func(experienceId int64) bool {
+		for _, experience := range s.experiences {
+			if experience.ID == experienceId {
+				return experience.profileId == int64(profileId)
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *MemoryStore) GetDistinctSkillsByExperience(ctx context.Context, experienceId int) ([]models.Skill, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.linkedSkills(func(id int64) bool {
+		return id == int64(experienceId) && s.hasExperience(id)
+	}), nil
+}
+
+// Existing skills linked to an experience accepted by the filter, by id
+func (s *MemoryStore) linkedSkills(experience func(experienceId int64) bool) []models.Skill {
+	var skills []models.Skill
+	for _, skill := range s.skills {
+		if slices.ContainsFunc(s.links, func(link memoryLink) bool {
+			return link.skillId == skill.ID && experience(link.experienceId)
+		}) {
+			skills = append(skills, skill)
+		}
+	}
+	return skills
+}
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, name string, hash string, scopes []string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, apiKey := range s.apiKeys {
+		if apiKey.Name == name {
+			return 0, fmt.Errorf("%w: api_key.name", errUniqueConstraint)
+		}
+		if apiKey.Hash == hash {
+			return 0, fmt.Errorf("%w: api_key.key_hash", errUniqueConstraint)
+		}
+	}
+
+	id := int64(1)
+	if len(s.apiKeys) > 0 {
+		id = s.apiKeys[len(s.apiKeys)-1].ID + 1
+	}
+	s.apiKeys = append(s.apiKeys, models.APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      hash,
+		Scopes:    strings.Fields(strings.Join(scopes, " ")),
+		CreatedAt: time.Now().UTC(),
+	})
+	return id, nil
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, apiKey := range s.apiKeys {
+		if apiKey.Hash == hash {
+			apiKey = cloneAPIKey(apiKey)
+			return &apiKey, nil
+		}
+	}
+	return nil, models.ErrAPIKeyNotFound
+}
+
+func (s *MemoryStore) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var apiKeys []models.APIKey
+	for _, apiKey := range s.apiKeys {
+		apiKeys = append(apiKeys, cloneAPIKey(apiKey))
+	}
+	return apiKeys, nil
+}
+
+func (s *MemoryStore) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == id {
+			usedAt := usedAt.UTC()
+			s.apiKeys[i].LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		if s.apiKeys[i].Name == name && s.apiKeys[i].RevokedAt == nil {
+			revokedAt := time.Now().UTC()
+			s.apiKeys[i].RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return models.ErrAPIKeyNotFound
+}
+
+func (s *MemoryStore) CreateOAuthClient(ctx context.Context, clientId string, name string, secretHash string, scopes []string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, client := range s.clients {
+		if client.ClientID == clientId {
+			return 0, fmt.Errorf("%w: oauth_client.client_id", errUniqueConstraint)
+		}
+	}
+
+	id := int64(1)
+	if len(s.clients) > 0 {
+		id = s.clients[len(s.clients)-1].ID + 1
+	}
+	s.clients = append(s.clients, models.OAuthClient{
+		ID:         id,
+		ClientID:   clientId,
+		Name:       name,
+		SecretHash: secretHash,
+		Scopes:     strings.Fields(strings.Join(scopes, " ")),
+		CreatedAt:  time.Now().UTC(),
+	})
+	return id, nil
+}
+
+func (s *MemoryStore) GetOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, client := range s.clients {
+		if client.ClientID == clientId {
+			client.Scopes = slices.Clone(client.Scopes)
+			client.RevokedAt = cloneTime(client.RevokedAt)
+			return &client, nil
+		}
+	}
+	return nil, models.ErrClientNotFound
+}
+
+func (s *MemoryStore) RevokeOAuthClient(ctx context.Context, clientId string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.clients {
+		if s.clients[i].ClientID == clientId && s.clients[i].RevokedAt == nil {
+			revokedAt := time.Now().UTC()
+			s.clients[i].RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return models.ErrClientNotFound
+}
+
+// Copy of an API key sharing nothing with the stored one
+func cloneAPIKey(apiKey models.APIKey) models.APIKey {
+	apiKey.Scopes = slices.Clone(apiKey.Scopes)
+	apiKey.LastUsedAt = cloneTime(apiKey.LastUsedAt)
+	apiKey.RevokedAt = cloneTime(apiKey.RevokedAt)
+	return apiKey
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
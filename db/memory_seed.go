@@ -0,0 +1,234 @@
+package db
+
+import (
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// First day of a month
+func seedDate(year int, month time.Month) time.Time {
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Static content of the resume held by a new MemoryStore,
+// the counterpart of the SQL seed and checked against it by the tests
+var seedProfiles = []models.Profile{
+	{
+		ID:         1,
+		FirstName:  "Florent",
+		LastName:   "Maillard",
+		Pronoun:    "He/Him",
+		Email:      "florent@maillard.icu",
+		Location:   "Switzeralnd - Vaud",
+		PostalCode: 1867,
+		Headline:   "Integration Expert",
+		About:      "There is no subsitute for hard Work./n- Thomas Edison",
+		BirthDate:  time.Date(1990, time.August, 21, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+var seedEducations = []memoryEducation{
+	{
+		profileId: 1,
+		Education: models.Education{
+			ID:          1,
+			Title:       "Université de Technologie de Compiègne (UTC)",
+			Description: "System and Network Engineer. Member of university sport team",
+			Issued:      seedDate(2014, time.September),
+		},
+	},
+}
+
+// The descriptions keep the literal \n of the SQL seed
+var seedExperiences = []memoryExperience{
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          1,
+			Title:       "Information Technology Engineer",
+			Company:     "CoDEM Picardie",
+			Location:    "Amiens",
+			Description: `Custom an ERP/CRM opensource software.\nR&D project management. Web app development and integration of 3 dimensions buildings data and point clouds`,
+			StartDate:   seedDate(2014, time.February),
+			EndDate:     seedDate(2016, time.July),
+		},
+	},
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          2,
+			Title:       "IT Project Manager / Lead developer",
+			Company:     "French public services",
+			Location:    "Beauvais",
+			Description: `Internal projects: Develop apps in a DevOps Team.\nExternal projects: Project specifications, budgets, plannings, leading IT service providers teams`,
+			StartDate:   seedDate(2016, time.August),
+			EndDate:     seedDate(2021, time.September),
+		},
+	},
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          3,
+			Title:       "Integration technical architect",
+			Company:     "Pocalin Hydraulics",
+			Location:    "Verberie",
+			Description: `Design and describe APIs using RAML, OpenAPI, AsyncAPI or GraphQL.\nDevelop Mule4 applications and achieve reliability implementing known design patterns and using platforms such as RabbitMQ / AnypointMQ or Apache Kafka.\nImplement CI/CD pipelines in Gitlab. Using Maven, mule cli and ansible.\nGovern APIs using Open Policy Agent or AMF. Enforce policies to ensure reliability, resilience\nand security (OAuth2, quotas, IP filtering, mTLS)\n\nOn-premise mule runtimes to Anypoint cloudhub 2.0 migration\n\nMulesoft connect 2022 speaker`,
+			StartDate:   seedDate(2021, time.September),
+			EndDate:     seedDate(2023, time.February),
+		},
+	},
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          4,
+			Title:       "Integration engineer",
+			Company:     "Vaudoise Assurances",
+			Location:    "Lausanne",
+			Description: `Build and run the existing wso2 clusters (API Manager and Identity Server)\n\nImprove or develop new custom wso2 components. From JWT token issuers and mediators to webapps\n\nFine tune the WSO2 engine\n\nStart the transition to the cloud for the API management. 6 environments created and designed to be fully managed using infrastructure as code`,
+			StartDate:   seedDate(2023, time.February),
+			EndDate:     seedDate(2024, time.April),
+		},
+	},
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          5,
+			Title:       "Interation expert",
+			Company:     "Vaudoise Assurances",
+			Location:    "Lausanne",
+			Description: `In addition to the preceding role\n\nLevel 3 support on all the integration platforms. Strong rise in skills on apache Kafka.\n\nManage the WSO2 platform migration project. Supporting teams during migration to the Cloud services.\n\nManage the lift and shift project to Confluent Cloud and Azure Kubernetes Service.\n\nImprove platforms logs and metrics in ELK. From custom Dashboards to watcher alerts.\n\nAutomate some admin common tasks with Azure DevOps pipelines.\n\nIntegrate all team's legacy projects into Jenkins and SonarQube\n\nPart of the the Vaudoise Azure community of practice. Defining standards and helping teams to achieve them.`,
+			StartDate:   seedDate(2024, time.April),
+		},
+	},
+	{
+		profileId: 1,
+		Experience: models.Experience{
+			ID:          6,
+			Title:       "Hhikig trail marker ",
+			Company:     "Vaud Rando",
+			Location:    "Lavey/Morcles",
+			Description: "Mark mountain hiking trails",
+			StartDate:   seedDate(2025, time.March),
+		},
+	},
+}
+
+var seedSkills = []models.Skill{
+	{ID: 1, Name: "PostgreSQL"},
+	{ID: 2, Name: "Git"},
+	{ID: 3, Name: "MySQL"},
+	{ID: 4, Name: "Podman"},
+	{ID: 5, Name: "Istio"},
+	{ID: 6, Name: "MongoDB"},
+	{ID: 7, Name: "Apache Kafka"},
+	{ID: 8, Name: "Maven"},
+	{ID: 9, Name: "OAuth2"},
+	{ID: 10, Name: "SAML"},
+	{ID: 11, Name: "Terraform"},
+	{ID: 12, Name: "GraphQL"},
+	{ID: 13, Name: "Ansible"},
+	{ID: 14, Name: "RabbitMQ"},
+	{ID: 15, Name: "OIDC"},
+	{ID: 16, Name: "Mulesoft"},
+	{ID: 17, Name: "API Management"},
+	{ID: 18, Name: "Kerberos"},
+	{ID: 19, Name: "Azure"},
+	{ID: 20, Name: "AWS"},
+	{ID: 21, Name: "GO"},
+	{ID: 22, Name: "Jenkins"},
+	{ID: 23, Name: "Azure DevOps"},
+	{ID: 24, Name: "AKS"},
+	{ID: 25, Name: "SonarQube"},
+	{ID: 26, Name: "KSql"},
+}
+
+// Skills of each experience
+var seedSkillLinks = map[int64][]int64{
+	1: {1, 2, 3},
+	2: {1, 2, 3, 4, 5, 6},
+	3: {7, 8, 1, 9, 10, 11, 12, 13, 14, 15, 16, 17},
+	4: {2, 7, 8, 9, 10, 11, 13, 14, 15, 17, 18, 19},
+	5: {26, 25, 24, 23, 22, 21, 20, 19},
+}
+
+var seedLicences = []memoryLicence{
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       1,
+			Title:    "Mulesoft Certified Platform Architect (MCPA)",
+			Issuer:   "Mulesoft",
+			Expires:  seedDate(2022, time.January),
+			IssuedAt: seedDate(2024, time.April),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       2,
+			Title:    "Mulesoft Certified Integration Architect (MCIA)",
+			Issuer:   "Mulesoft",
+			Expires:  seedDate(2022, time.January),
+			IssuedAt: seedDate(2024, time.April),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       3,
+			Title:    "Confluent Certified Developer for Apache Kafka",
+			Issuer:   "Confluent",
+			Expires:  seedDate(2023, time.January),
+			IssuedAt: seedDate(2025, time.January),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       4,
+			Title:    "Microsoft Certified: Cybersecurity Architect Expert",
+			Issuer:   "Mulesoft",
+			Expires:  seedDate(2024, time.February),
+			IssuedAt: seedDate(2025, time.February),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       5,
+			Title:    "Microsoft Certified : Azure Security Engineer Associate",
+			Issuer:   "Mulesoft",
+			Expires:  seedDate(2024, time.February),
+			IssuedAt: seedDate(2025, time.February),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       6,
+			Title:    "CKAD",
+			Issuer:   "The Linux Foundation",
+			Expires:  seedDate(2022, time.October),
+			IssuedAt: seedDate(2025, time.October),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       7,
+			Title:    "WSO2 Certified API Manager",
+			Issuer:   "WSO2",
+			IssuedAt: seedDate(2022, time.December),
+		},
+	},
+	{
+		profileId: 1,
+		Licence: models.Licence{
+			ID:       8,
+			Title:    "Scrum Basics",
+			Issuer:   "Scrum INC",
+			IssuedAt: seedDate(2025, time.September),
+		},
+	},
+}
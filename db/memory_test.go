@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConformanceMemory(t *testing.T) {
+	runContract(t, NewMemoryStore())
+}
+
+// The resume held by a new MemoryStore is the one seeded by the migrations
+func TestMemorySeedMatchesSQL(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer conn.Close()
+	if err := Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	store := NewStoreFromSQLDB(conn, 5*time.Second)
+	memory := NewMemoryStore()
+
+	compare := func(name string, fetch func(s Storage) (any, error)) {
+		want, err := fetch(store)
+		if err != nil {
+			t.Fatalf("%s on the SQL store: %v", name, err)
+		}
+		got, err := fetch(memory)
+		if err != nil {
+			t.Fatalf("%s on the memory store: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s differs\nmemory: %+v\nsql:    %+v", name, got, want)
+		}
+	}
+
+	compare("GetProfiles", func(s Storage) (any, error) { return s.GetProfiles(ctx) })
+	compare("GetDistinctEducationsByProfile", func(s Storage) (any, error) { return s.GetDistinctEducationsByProfile(ctx, 1) })
+	compare("GetDistinctExperiencesByProfile", func(s Storage) (any, error) { return s.GetDistinctExperiencesByProfile(ctx, 1) })
+	compare("GetDistinctLicencesByProfile", func(s Storage) (any, error) { return s.GetDistinctLicencesByProfile(ctx, 1) })
+	compare("GetDistinctSkills", func(s Storage) (any, error) { return s.GetDistinctSkills(ctx) })
+	compare("GetDistinctSkillsByProfile", func(s Storage) (any, error) {
+		skills, err := s.GetDistinctSkillsByProfile(ctx, 1)
+		return skillIds(skills), err
+	})
+	for experienceId := 1; experienceId <= 6; experienceId++ {
+		compare("GetDistinctSkillsByExperience", func(s Storage) (any, error) {
+			skills, err := s.GetDistinctSkillsByExperience(ctx, experienceId)
+			return skillIds(skills), err
+		})
+	}
+}
+
+// Callers get copies, the stored content is left untouched
+func TestMemoryStoreReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	skills, _ := store.GetDistinctSkills(ctx)
+	skills[0].Name = "changed"
+
+	id, _ := store.CreateAPIKey(ctx, "batch", "hash", []string{"read"})
+	store.TouchAPIKey(ctx, id, time.Now())
+	apiKey, _ := store.GetAPIKeyByHash(ctx, "hash")
+	apiKey.Scopes[0] = "admin"
+	*apiKey.LastUsedAt = time.Time{}
+
+	skills, _ = store.GetDistinctSkills(ctx)
+	if skills[0].Name != "PostgreSQL" {
+		t.Errorf("expected the stored skill to be unchanged, got %q", skills[0].Name)
+	}
+	apiKey, _ = store.GetAPIKeyByHash(ctx, "hash")
+	if apiKey.Scopes[0] != "read" || apiKey.LastUsedAt.IsZero() {
+		t.Errorf("expected the stored api key to be unchanged, got %+v", apiKey)
+	}
+}
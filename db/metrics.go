@@ -0,0 +1,41 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/flmailla/resume/internal/metrics"
+)
+
+// Connection pool statistics, exposed when scraped
+var (
+	poolOpenConnections = metrics.NewGaugeFunc("resume_db_open_connections",
+		"Established connections, in use or idle.", func() float64 { return float64(poolStats().OpenConnections) })
+	poolInUseConnections = metrics.NewGaugeFunc("resume_db_in_use_connections",
+		"Connections currently in use.", func() float64 { return float64(poolStats().InUse) })
+	poolIdleConnections = metrics.NewGaugeFunc("resume_db_idle_connections",
+		"Idle connections.", func() float64 { return float64(poolStats().Idle) })
+	poolMaxOpenConnections = metrics.NewGaugeFunc("resume_db_max_open_connections",
+		"Maximum number of open connections, 0 when unlimited.", func() float64 { return float64(poolStats().MaxOpenConnections) })
+	poolWaitCount = metrics.NewCounterFunc("resume_db_wait_count_total",
+		"Connections waited for.", func() float64 { return float64(poolStats().WaitCount) })
+	poolWaitDuration = metrics.NewCounterFunc("resume_db_wait_duration_seconds_total",
+		"Time blocked waiting for a connection.", func() float64 { return poolStats().WaitDuration.Seconds() })
+)
+
+// Statistics of the opened database, its pools summed, empty before InitDB
+func poolStats() sql.DBStats {
+	var stats sql.DBStats
+	for _, db := range []*sql.DB{DB, ReadDB} {
+		if db == nil {
+			continue
+		}
+		pool := db.Stats()
+		stats.MaxOpenConnections += pool.MaxOpenConnections
+		stats.OpenConnections += pool.OpenConnections
+		stats.InUse += pool.InUse
+		stats.Idle += pool.Idle
+		stats.WaitCount += pool.WaitCount
+		stats.WaitDuration += pool.WaitDuration
+	}
+	return stats
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Storage decorator calling changed once the resume content is written,
+// e.g. to drop the responses cached from it. API keys and clients are
+// not part of the served content. Transactions are run through WithTx,
+// their writes being unknown until they commit.
+type NotifyingStore struct {
+	Storage
+	changed func()
+}
+
+func NotifyChanges(store Storage, changed func()) *NotifyingStore {
+	return &NotifyingStore{Storage: store, changed: changed}
+}
+
+func (s *NotifyingStore) CreateExperience(ctx context.Context, profileId int, experience models.Experience) (int64, error) {
+	id, err := s.Storage.CreateExperience(ctx, profileId, experience)
+	if err == nil {
+		s.changed()
+	}
+	return id, err
+}
+
+func (s *NotifyingStore) LinkSkillToExperience(ctx context.Context, experienceId int64, skillId int64) error {
+	err := s.Storage.LinkSkillToExperience(ctx, experienceId, skillId)
+	if err == nil {
+		s.changed()
+	}
+	return err
+}
+
+// Run fn in a transaction of the decorated SQL store, calling changed once
+// it commits. Storages without transactions refuse it.
+func (s *NotifyingStore) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	store, ok := s.Storage.(*Store)
+	if !ok {
+		return fmt.Errorf("transactions %w by this storage", models.ErrNotImplemented)
+	}
+	if err := store.WithTx(ctx, fn); err != nil {
+		return err
+	}
+	s.changed()
+	return nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestNotifyChanges(t *testing.T) {
+	ctx := context.Background()
+	changes := 0
+	store := NotifyChanges(NewMemoryStore(), func() { changes++ })
+
+	id, err := store.CreateExperience(ctx, 1, models.Experience{Title: "Cache", StartDate: time.Now()})
+	if err != nil {
+		t.Fatalf("CreateExperience() = %v", err)
+	}
+	if err := store.LinkSkillToExperience(ctx, id, 1); err != nil {
+		t.Fatalf("LinkSkillToExperience() = %v", err)
+	}
+	if changes != 2 {
+		t.Errorf("expected a change per write, got %d", changes)
+	}
+
+	store.LinkSkillToExperience(ctx, id, 404)
+	store.CreateAPIKey(ctx, "ci", "hash", nil)
+	if changes != 2 {
+		t.Errorf("expected failed writes and api keys not to count, got %d changes", changes)
+	}
+}
+
+func TestNotifyChangesOnCommit(t *testing.T) {
+	ctx := context.Background()
+	changes := 0
+	store := NotifyChanges(NewStore(&MockDB{
+		beginFunc: func() (TxInterface, error) { return &MockTx{}, nil },
+	}), func() { changes++ })
+
+	err := store.WithTx(ctx, func(tx *Store) error {
+		return tx.LinkSkillToExperience(ctx, 1, 2)
+	})
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+	if changes != 1 {
+		t.Errorf("expected a change once committed, got %d", changes)
+	}
+
+	errFailed := errors.New("failed")
+	if err := store.WithTx(ctx, func(tx *Store) error { return errFailed }); !errors.Is(err, errFailed) {
+		t.Fatalf("WithTx() = %v, want %v", err, errFailed)
+	}
+	if changes != 1 {
+		t.Errorf("expected a rolled back transaction not to count, got %d changes", changes)
+	}
+
+	memory := NotifyChanges(NewMemoryStore(), func() { changes++ })
+	if err := memory.WithTx(ctx, func(tx *Store) error { return nil }); !errors.Is(err, models.ErrNotImplemented) {
+		t.Errorf("WithTx() = %v, want %v", err, models.ErrNotImplemented)
+	}
+}
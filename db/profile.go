@@ -1,13 +1,15 @@
 package db
 
 import (
+	"context"
+
 	"github.com/flmailla/resume/models"
 )
 
-func (s *Store) GetProfileById(ProfileId int) (*models.Profile, error) {
+func (s *Store) GetProfileById(ctx context.Context, ProfileId int) (*models.Profile, error) {
 	var profile models.Profile
 	query := "SELECT id, firstname, lastname, pronoun, email, location, postal_code, headline, about, birthdate FROM profile WHERE id = ?"
-	err := s.db.QueryRow(query, ProfileId).Scan(
+	err := s.queryRowContext(ctx, query, ProfileId).Scan(
 		&profile.ID,
 		&profile.FirstName,
 		&profile.LastName,
@@ -24,9 +26,9 @@ func (s *Store) GetProfileById(ProfileId int) (*models.Profile, error) {
 	return &profile, nil
 }
 
-func (s *Store) GetProfiles() ([]models.Profile, error) {
-	query := "SELECT id, firstname, lastname, pronoun, email, location, postal_code, headline, about, birthdate FROM profile"
-	rows, err := s.db.Query(query)
+func (s *Store) GetProfiles(ctx context.Context) ([]models.Profile, error) {
+	query := "SELECT id, firstname, lastname, pronoun, email, location, postal_code, headline, about, birthdate FROM profile ORDER BY id"
+	rows, err := s.queryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -117,7 +118,7 @@ func TestGetProfiles(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetProfiles()
+			got, err := store.GetProfiles(context.Background())
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
@@ -235,7 +236,7 @@ func TestGetProfileById(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetProfileById(1)
+			got, err := store.GetProfileById(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
@@ -1,12 +1,14 @@
 package db
 
 import (
+	"context"
+
 	"github.com/flmailla/resume/models"
 )
 
-func (s *Store) GetDistinctSkills() ([]models.Skill, error) {
-	query := "SELECT DISTINCT id, name FROM skill"
-	rows, err := s.db.Query(query)
+func (s *Store) GetDistinctSkills(ctx context.Context) ([]models.Skill, error) {
+	query := "SELECT DISTINCT id, name FROM skill ORDER BY id"
+	rows, err := s.queryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -30,12 +32,13 @@ func (s *Store) GetDistinctSkills() ([]models.Skill, error) {
 	return skills, nil
 }
 
-func (s *Store) GetDistinctSkillsByProfile(profileId int) ([]models.Skill, error) {
+func (s *Store) GetDistinctSkillsByProfile(ctx context.Context, profileId int) ([]models.Skill, error) {
 	query := `SELECT DISTINCT s.id, s.name FROM skill as s
 				JOIN skill_experience as se ON se.skill_id = s.id
 				JOIN experience as e ON e.id = se.experience_id
-				Where e.profile_id = ?`
-	rows, err := s.db.Query(query, profileId)
+				Where e.profile_id = ?
+				ORDER BY s.id`
+	rows, err := s.queryContext(ctx, query, profileId)
 	if err != nil {
 		return nil, err
 	}
@@ -57,12 +60,13 @@ func (s *Store) GetDistinctSkillsByProfile(profileId int) ([]models.Skill, error
 	return skills, nil
 }
 
-func (s *Store) GetDistinctSkillsByExperience(experienceId int) ([]models.Skill, error) {
+func (s *Store) GetDistinctSkillsByExperience(ctx context.Context, experienceId int) ([]models.Skill, error) {
 	query := `SELECT DISTINCT s.id, s.name FROM skill as s
 				JOIN skill_experience as se ON se.skill_id = s.id
 				JOIN experience as e ON e.id = se.experience_id
-				Where e.id = ?`
-	rows, err := s.db.Query(query, experienceId)
+				Where e.id = ?
+				ORDER BY s.id`
+	rows, err := s.queryContext(ctx, query, experienceId)
 	if err != nil {
 		return nil, err
 	}
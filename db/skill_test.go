@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -96,7 +97,7 @@ func TestGetSkillsByXXX(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctSkillsByProfile(1)
+			got, err := store.GetDistinctSkillsByProfile(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Store.GetSkillsByProfile() error = %v, wantErr %v", err, tt.wantErr)
@@ -121,7 +122,7 @@ func TestGetSkillsByXXX(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctSkills()
+			got, err := store.GetDistinctSkills(context.Background())
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Store.GetSkills() error = %v, wantErr %v", err, tt.wantErr)
@@ -146,7 +147,7 @@ func TestGetSkillsByXXX(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewStore(tt.mockDB)
 
-			got, err := store.GetDistinctSkillsByExperience(1)
+			got, err := store.GetDistinctSkillsByExperience(context.Background(), 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Store.GetSkillsByExperience() error = %v, wantErr %v", err, tt.wantErr)
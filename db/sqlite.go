@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tuning of the SQLite connections, applied to each of them
+// See https://www.sqlite.org/pragma.html
+type SQLiteOptions struct {
+	JournalMode string        // DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF
+	Synchronous string        // OFF, NORMAL, FULL or EXTRA
+	BusyTimeout time.Duration // wait for a locked database before failing with SQLITE_BUSY
+	ForeignKeys bool
+	// Size of the read only pool, 0 to read from the write connection
+	ReadConns      int
+	IntegrityCheck bool // check the file when opening it
+}
+
+var DefaultSQLiteOptions = SQLiteOptions{
+	JournalMode:    "WAL",
+	Synchronous:    "NORMAL",
+	BusyTimeout:    5 * time.Second,
+	ForeignKeys:    true,
+	ReadConns:      4,
+	IntegrityCheck: true,
+}
+
+// Open a SQLite database as two pools: a single connection for the writes,
+// so that they queue in the process instead of failing with SQLITE_BUSY,
+// and read only connections. The read pool is nil when the options ask for
+// none, or for an in-memory database that a second pool would not share.
+func OpenSQLite(dsn string, options SQLiteOptions) (write *sql.DB, read *sql.DB, err error) {
+	params := url.Values{}
+	params.Set("_busy_timeout", strconv.FormatInt(options.BusyTimeout.Milliseconds(), 10))
+	params.Set("_synchronous", strings.ToUpper(options.Synchronous))
+	if options.ForeignKeys {
+		params.Set("_foreign_keys", "1")
+	} else {
+		params.Set("_foreign_keys", "0")
+	}
+
+	readParams := url.Values{}
+	for key, values := range params {
+		readParams[key] = values
+	}
+	readParams.Set("_query_only", "1")
+
+	// The journal mode is persisted in the file, the write pool setting it
+	params.Set("_journal_mode", strings.ToUpper(options.JournalMode))
+
+	write, err = sql.Open(SQLite.Driver, withParams(dsn, params))
+	if err != nil {
+		return nil, nil, err
+	}
+	write.SetMaxOpenConns(1)
+
+	if options.ReadConns == 0 || isMemoryDSN(dsn) {
+		return write, nil, nil
+	}
+	read, err = sql.Open(SQLite.Driver, withParams(dsn, readParams))
+	if err != nil {
+		write.Close()
+		return nil, nil, err
+	}
+	read.SetMaxOpenConns(options.ReadConns)
+	read.SetMaxIdleConns(options.ReadConns)
+	return write, read, nil
+}
+
+// Check the database file, reporting the first problems found
+func IntegrityCheck(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Add the driver parameters to a SQLite data source name,
+// its own parameters taking precedence
+func withParams(dsn string, params url.Values) string {
+	path, query, _ := strings.Cut(dsn, "?")
+	own, err := url.ParseQuery(query)
+	if err != nil {
+		// Left for the driver to report
+		return dsn
+	}
+	for key, values := range own {
+		params[key] = values
+	}
+	return path + "?" + params.Encode()
+}
+
+func isMemoryDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
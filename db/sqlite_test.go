@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+	write, read, err := OpenSQLite(filepath.Join(t.TempDir(), "resume.db"), DefaultSQLiteOptions)
+	if err != nil {
+		t.Fatalf("OpenSQLite() = %v", err)
+	}
+	defer write.Close()
+	defer read.Close()
+	if err := Migrate(write); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	pragmas := []struct {
+		pool  *sql.DB
+		name  string
+		value string
+	}{
+		{write, "journal_mode", "wal"},
+		{write, "synchronous", "1"},
+		{write, "busy_timeout", "5000"},
+		{write, "foreign_keys", "1"},
+		{write, "query_only", "0"},
+		{read, "journal_mode", "wal"},
+		{read, "foreign_keys", "1"},
+		{read, "query_only", "1"},
+	}
+	for _, pragma := range pragmas {
+		var value string
+		if err := pragma.pool.QueryRowContext(ctx, "PRAGMA "+pragma.name).Scan(&value); err != nil {
+			t.Fatalf("PRAGMA %s: %v", pragma.name, err)
+		}
+		if value != pragma.value {
+			t.Errorf("expected %s = %s, got %s", pragma.name, pragma.value, value)
+		}
+	}
+
+	if stats := write.Stats(); stats.MaxOpenConnections != 1 {
+		t.Errorf("expected a single write connection, got %d", stats.MaxOpenConnections)
+	}
+	if _, err := read.ExecContext(ctx, "DELETE FROM skill"); err == nil {
+		t.Error("expected the read pool to refuse writes")
+	}
+	if _, err := write.ExecContext(ctx, "INSERT INTO skill_experience (experience_id, skill_id) VALUES (1, 404)"); err == nil {
+		t.Error("expected the foreign keys to be enforced")
+	}
+}
+
+func TestOpenSQLiteInMemory(t *testing.T) {
+	write, read, err := OpenSQLite(":memory:", DefaultSQLiteOptions)
+	if err != nil {
+		t.Fatalf("OpenSQLite() = %v", err)
+	}
+	defer write.Close()
+
+	if read != nil {
+		t.Error("expected no read pool, it would open another in-memory database")
+	}
+	if err := Migrate(write); err != nil {
+		t.Errorf("failed to migrate: %v", err)
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	params := func() map[string][]string {
+		return map[string][]string{"_busy_timeout": {"5000"}, "_foreign_keys": {"1"}}
+	}
+
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"./resume.db", "./resume.db?_busy_timeout=5000&_foreign_keys=1"},
+		{"file:resume.db?cache=shared", "file:resume.db?_busy_timeout=5000&_foreign_keys=1&cache=shared"},
+		{"resume.db?_foreign_keys=0", "resume.db?_busy_timeout=5000&_foreign_keys=0"},
+	}
+	for _, tt := range tests {
+		if got := withParams(tt.dsn, params()); got != tt.want {
+			t.Errorf("withParams(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "resume.db")
+	options := DefaultSQLiteOptions
+	options.JournalMode = "DELETE"
+
+	write, _, err := OpenSQLite(path, options)
+	if err != nil {
+		t.Fatalf("OpenSQLite() = %v", err)
+	}
+	if err := Migrate(write); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := IntegrityCheck(ctx, write); err != nil {
+		t.Errorf("IntegrityCheck() = %v on a sound database", err)
+	}
+	write.Close()
+
+	// Scramble the pages following the header and the schema
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 4096)
+	for i := range garbage {
+		garbage[i] = 0xA5
+	}
+	if _, err := file.WriteAt(garbage, 2*4096); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	write, _, err = OpenSQLite(path, options)
+	if err != nil {
+		t.Fatalf("OpenSQLite() = %v", err)
+	}
+	defer write.Close()
+	if err := IntegrityCheck(ctx, write); err == nil {
+		t.Error("IntegrityCheck() = nil on a corrupted database")
+	}
+}
+
+// Reads racing a steady stream of writes, on a single pool with the SQLite
+// defaults, then on the tuned write and read pools
+//
+//	go test ./db -run - -bench ConcurrentReads
+func BenchmarkConcurrentReads(b *testing.B) {
+	open := map[string]func(path string) (*sql.DB, *sql.DB, error){
+		"defaults": func(path string) (*sql.DB, *sql.DB, error) {
+			db, err := sql.Open("sqlite3", path)
+			return db, nil, err
+		},
+		"pools": func(path string) (*sql.DB, *sql.DB, error) {
+			return OpenSQLite(path, DefaultSQLiteOptions)
+		},
+	}
+
+	for _, name := range []string{"defaults", "pools"} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			write, read, err := open[name](filepath.Join(b.TempDir(), "resume.db"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer write.Close()
+			if read != nil {
+				defer read.Close()
+			}
+			if err := Migrate(write); err != nil {
+				b.Fatal(err)
+			}
+			store := NewStoreFromPools(write, read, 0)
+			id, err := store.CreateAPIKey(ctx, "bench", "bench-hash", nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			stop := make(chan struct{})
+			var writer sync.WaitGroup
+			writer.Add(1)
+			go func() {
+				defer writer.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						store.TouchAPIKey(ctx, id, time.Now())
+					}
+				}
+			}()
+
+			var failures atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := store.GetDistinctSkillsByProfile(ctx, 1); err != nil {
+						failures.Add(1)
+					}
+				}
+			})
+			b.StopTimer()
+			close(stop)
+			writer.Wait()
+			b.ReportMetric(float64(failures.Load())/float64(b.N), "failures/op")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Statements of a store, each prepared on its first run then reused
+// by the following ones. A statement failing to prepare is not kept,
+// the next run preparing it again. Statements are prepared outside of
+// the lock, which would otherwise hold every lookup while the single
+// SQLite write connection is busy with a transaction.
+type stmtCache struct {
+	conn  DBInterface
+	mu    sync.RWMutex
+	stmts map[string]StmtInterface
+}
+
+func newStmtCache(conn DBInterface) *stmtCache {
+	return &stmtCache{conn: conn, stmts: make(map[string]StmtInterface)}
+}
+
+func (c *stmtCache) get(ctx context.Context, query string) (StmtInterface, error) {
+	c.mu.RLock()
+	stmt, ok := c.stmts[query]
+	c.mu.RUnlock()
+	if ok {
+		return stmt, nil
+	}
+
+	stmt, err := c.conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Prepared meanwhile by a concurrent run, whose statement is kept
+	if kept, ok := c.stmts[query]; ok {
+		stmt.Close()
+		return kept, nil
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// Close the statements prepared so far, the runs that follow preparing theirs again
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for query, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// Close the prepared statements of the store, to be called before CloseDB
+func (s *Store) Close() error {
+	if s.stmts == nil {
+		return nil
+	}
+	return s.stmts.close()
+}
+
+// The statements of a store bound to a transaction, which prepares none,
+// are run directly
+func (s *Store) queryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	if s.stmts == nil {
+		return s.db.QueryContext(ctx, query, args...)
+	}
+	stmt, err := s.stmts.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (s *Store) queryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	if s.stmts == nil {
+		return s.db.QueryRowContext(ctx, query, args...)
+	}
+	stmt, err := s.stmts.get(ctx, query)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+func (s *Store) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if s.stmts == nil {
+		return s.db.ExecContext(ctx, query, args...)
+	}
+	stmt, err := s.stmts.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// Row of a statement that failed to prepare, returning the error on scan
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...interface{}) error {
+	return r.err
+}
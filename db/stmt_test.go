@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreReusesStatements(t *testing.T) {
+	ctx := context.Background()
+	failPrepare := true
+	mock := &MockDB{
+		prepareFunc: func(query string) error {
+			if failPrepare {
+				return errors.New("database is locked")
+			}
+			return nil
+		},
+		queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+			return &MockRows{}, nil
+		},
+		execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 1}, nil
+		},
+		beginFunc: func() (TxInterface, error) {
+			return &MockTx{}, nil
+		},
+	}
+	store := NewStore(mock)
+
+	if _, err := store.GetDistinctSkillsByProfile(ctx, 1); err == nil {
+		t.Fatal("expected the preparation error")
+	}
+	failPrepare = false
+	for i := 0; i < 3; i++ {
+		if _, err := store.GetDistinctSkillsByProfile(ctx, i); err != nil {
+			t.Fatalf("GetDistinctSkillsByProfile() = %v", err)
+		}
+	}
+	store.GetDistinctSkills(ctx)
+	if len(mock.stmts) != 2 {
+		t.Fatalf("expected a statement prepared per query, got %d", len(mock.stmts))
+	}
+
+	// Transactions run their statements directly
+	store.WithTx(ctx, func(tx *Store) error {
+		return tx.LinkSkillToExperience(ctx, 1, 1)
+	})
+	if len(mock.stmts) != 2 {
+		t.Errorf("expected no statement prepared in a transaction, got %d", len(mock.stmts))
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	for _, stmt := range mock.stmts {
+		if !stmt.closed {
+			t.Errorf("expected %q to be closed", stmt.query)
+		}
+	}
+}
+
+func TestStorePreparesOnPools(t *testing.T) {
+	ctx := context.Background()
+	write, read := openTestSQLite(t, filepath.Join(t.TempDir(), "resume.db"))
+	store := NewStoreFromPools(write, read, 0)
+
+	if _, err := store.CreateAPIKey(ctx, "ci", "hash", []string{"read"}); err != nil {
+		t.Fatalf("CreateAPIKey() = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		apiKey, err := store.GetAPIKeyByHash(ctx, "hash")
+		if err != nil || apiKey.Name != "ci" {
+			t.Fatalf("GetAPIKeyByHash() = %v, %v", apiKey, err)
+		}
+	}
+	if err := store.RevokeAPIKey(ctx, "ci"); err != nil {
+		t.Fatalf("RevokeAPIKey() = %v", err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	// Prepared again once closed
+	if err := store.Ping(ctx); err != nil {
+		t.Errorf("Ping() = %v after Close", err)
+	}
+	store.Close()
+}
+
+// Connection whose preparations wait to be released, like the SQLite
+// write connection held by a transaction
+type blockedConn struct {
+	MockDB
+	started chan string
+	release chan struct{}
+
+	mu       sync.Mutex
+	prepared []*MockStmt
+}
+
+func (c *blockedConn) PrepareContext(ctx context.Context, query string) (StmtInterface, error) {
+	c.started <- query
+	<-c.release
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stmt := &MockStmt{db: &c.MockDB, query: query}
+	c.prepared = append(c.prepared, stmt)
+	return stmt, nil
+}
+
+func TestStmtCachePreparesOutsideTheLock(t *testing.T) {
+	ctx := context.Background()
+	conn := &blockedConn{started: make(chan string, 2), release: make(chan struct{})}
+	cache := newStmtCache(conn)
+	cache.stmts["SELECT 1"] = &MockStmt{query: "SELECT 1"}
+
+	results := make(chan StmtInterface, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			stmt, _ := cache.get(ctx, "SELECT 2")
+			results <- stmt
+		}()
+	}
+	<-conn.started
+	<-conn.started
+
+	// Both preparing, the statements already prepared are still served
+	lookup := make(chan struct{})
+	go func() {
+		cache.get(ctx, "SELECT 1")
+		close(lookup)
+	}()
+	select {
+	case <-lookup:
+	case <-time.After(time.Second):
+		t.Fatal("expected the lookup not to wait for the preparations")
+	}
+
+	close(conn.release)
+	first, second := <-results, <-results
+	if first != second || cache.stmts["SELECT 2"] != first {
+		t.Errorf("expected both runs to get the statement kept, got %p and %p", first, second)
+	}
+	closed := 0
+	for _, stmt := range conn.prepared {
+		if stmt.closed {
+			closed++
+		}
+	}
+	if len(conn.prepared) != 2 || closed != 1 {
+		t.Errorf("expected the statement of the losing run to be closed, got %d closed out of %d", closed, len(conn.prepared))
+	}
+}
+
+// Latency of a query parsed on every run, then prepared once
+//
+//	go test ./db -run - -bench GetDistinctSkillsByProfile
+func BenchmarkGetDistinctSkillsByProfile(b *testing.B) {
+	for _, name := range []string{"unprepared", "prepared"} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			write, read, err := OpenSQLite(filepath.Join(b.TempDir(), "resume.db"), DefaultSQLiteOptions)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer write.Close()
+			defer read.Close()
+			if err := Migrate(write); err != nil {
+				b.Fatal(err)
+			}
+			store := NewStoreFromPools(write, read, 0)
+			defer store.Close()
+			if name == "unprepared" {
+				store.stmts = nil
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.GetDistinctSkillsByProfile(ctx, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
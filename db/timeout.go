@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DBInterface decorator bounding the duration of every statement,
+// on top of the deadline and cancellation of the caller context
+type TimeoutDB struct {
+	timeoutQuerier
+	db DBInterface
+}
+
+// Bound the statements to the given timeout, none when not positive
+func NewTimeoutDB(db DBInterface, timeout time.Duration) DBInterface {
+	if timeout <= 0 {
+		return db
+	}
+	return &TimeoutDB{timeoutQuerier: timeoutQuerier{q: db, timeout: timeout}, db: db}
+}
+
+// Begin a transaction whose statements are bounded as well,
+// the transaction itself only following the caller context
+func (t *TimeoutDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (TxInterface, error) {
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &timeoutTx{timeoutQuerier: timeoutQuerier{q: tx, timeout: t.timeout}, tx: tx}, nil
+}
+
+// Prepare a statement within the timeout, its runs being bounded as well
+func (t *TimeoutDB) PrepareContext(ctx context.Context, query string) (StmtInterface, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	stmt, err := t.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	bounded := &timeoutQuerier{q: stmtQuerier{stmt: stmt}, timeout: t.timeout}
+	return &querierStmt{q: bounded, query: query, stmt: stmt}, nil
+}
+
+type timeoutTx struct {
+	timeoutQuerier
+	tx TxInterface
+}
+
+func (t *timeoutTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *timeoutTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Statements of a database or of a transaction, bounded
+type timeoutQuerier struct {
+	q       Querier
+	timeout time.Duration
+}
+
+func (t *timeoutQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelRows{RowsInterface: rows, cancel: cancel}, nil
+}
+
+func (t *timeoutQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	return &cancelRow{RowInterface: t.q.QueryRowContext(ctx, query, args...), cancel: cancel}
+}
+
+func (t *timeoutQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.q.ExecContext(ctx, query, args...)
+}
+
+// Rows releasing their timeout once closed
+type cancelRows struct {
+	RowsInterface
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Close() error {
+	err := r.RowsInterface.Close()
+	r.cancel()
+	return err
+}
+
+// Row releasing its timeout once scanned
+type cancelRow struct {
+	RowInterface
+	cancel context.CancelFunc
+}
+
+func (r *cancelRow) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.RowInterface.Scan(dest...)
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutDB(t *testing.T) {
+	mock := &MockDB{
+		queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+			return &MockRows{}, nil
+		},
+		queryRowFunc: func(query string, args ...interface{}) RowInterface {
+			return &MockRow{scanFunc: func(dest ...interface{}) error { return nil }}
+		},
+		execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	if NewTimeoutDB(mock, 0) != DBInterface(mock) {
+		t.Error("expected no decorator without timeout")
+	}
+
+	timeoutDB := NewTimeoutDB(mock, time.Minute)
+
+	assertDeadline := func(t *testing.T) {
+		t.Helper()
+		deadline, ok := mock.ctx.Deadline()
+		if !ok || time.Until(deadline) > time.Minute {
+			t.Errorf("expected a deadline within a minute, got %v (set: %v)", deadline, ok)
+		}
+	}
+
+	t.Run("query released on close", func(t *testing.T) {
+		rows, err := timeoutDB.QueryContext(context.Background(), "SELECT name FROM skill")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertDeadline(t)
+		if mock.ctx.Err() != nil {
+			t.Error("expected the context to be alive until the rows are closed")
+		}
+		rows.Close()
+		if !errors.Is(mock.ctx.Err(), context.Canceled) {
+			t.Errorf("expected the context to be released, got %v", mock.ctx.Err())
+		}
+	})
+
+	t.Run("row released on scan", func(t *testing.T) {
+		row := timeoutDB.QueryRowContext(context.Background(), "SELECT 1")
+		assertDeadline(t)
+		row.Scan()
+		if !errors.Is(mock.ctx.Err(), context.Canceled) {
+			t.Errorf("expected the context to be released, got %v", mock.ctx.Err())
+		}
+	})
+
+	t.Run("exec released on return", func(t *testing.T) {
+		if _, err := timeoutDB.ExecContext(context.Background(), "UPDATE api_key SET name = ?", "batch"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertDeadline(t)
+		if !errors.Is(mock.ctx.Err(), context.Canceled) {
+			t.Errorf("expected the context to be released, got %v", mock.ctx.Err())
+		}
+	})
+
+	t.Run("prepared statement bounded", func(t *testing.T) {
+		stmt, err := timeoutDB.PrepareContext(context.Background(), "UPDATE api_key SET name = ?")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertDeadline(t)
+		stmt.ExecContext(context.Background(), "batch")
+		assertDeadline(t)
+		if !errors.Is(mock.ctx.Err(), context.Canceled) {
+			t.Errorf("expected the context to be released, got %v", mock.ctx.Err())
+		}
+	})
+
+	t.Run("caller deadline kept when shorter", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		timeoutDB.QueryRowContext(ctx, "SELECT 1")
+		if deadline, _ := mock.ctx.Deadline(); time.Until(deadline) > time.Millisecond {
+			t.Errorf("expected the caller deadline, got %v", deadline)
+		}
+	})
+}
+
+func TestStorePropagatesContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+
+	mock := &MockDB{
+		queryRowFunc: func(query string, args ...interface{}) RowInterface {
+			return &MockRow{scanFunc: func(dest ...interface{}) error { return nil }}
+		},
+	}
+	store := NewStore(mock)
+
+	store.GetProfileById(ctx, 1)
+
+	if mock.ctx == nil || mock.ctx.Value(key{}) != "request" {
+		t.Error("expected the caller context to reach the database")
+	}
+}
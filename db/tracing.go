@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/flmailla/resume/db")
+
+// DBInterface decorator recording a client span per statement,
+// with the statement text and the number of rows returned or affected
+type TracedDB struct {
+	tracedQuerier
+	db DBInterface
+}
+
+func NewTracedDB(db DBInterface, system string) *TracedDB {
+	return &TracedDB{tracedQuerier: tracedQuerier{q: db, system: system}, db: db}
+}
+
+// Begin a transaction whose statements are traced as well
+func (t *TracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (TxInterface, error) {
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{tracedQuerier: tracedQuerier{q: tx, system: t.system}, tx: tx}, nil
+}
+
+// Prepare a statement whose runs are traced, as the statements of the database
+func (t *TracedDB) PrepareContext(ctx context.Context, query string) (StmtInterface, error) {
+	stmt, err := t.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traced := &tracedQuerier{q: stmtQuerier{stmt: stmt}, system: t.system}
+	return &querierStmt{q: traced, query: query, stmt: stmt}, nil
+}
+
+type tracedTx struct {
+	tracedQuerier
+	tx TxInterface
+}
+
+func (t *tracedTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *tracedTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Statements of a database or of a transaction, traced
+type tracedQuerier struct {
+	q      Querier
+	system string
+}
+
+// Start a span named after the statement operation, e.g. SELECT
+func (t *tracedQuerier) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return tracer.Start(ctx, strings.ToUpper(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", t.system),
+			attribute.String("db.query.text", strings.Join(strings.Fields(query), " ")),
+		))
+}
+
+func (t *tracedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{RowsInterface: rows, span: span}, nil
+}
+
+func (t *tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	ctx, span := t.start(ctx, query)
+	return &tracedRow{RowInterface: t.q.QueryRowContext(ctx, query, args...), span: span}
+}
+
+func (t *tracedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	result, err := t.q.ExecContext(ctx, query, args...)
+	if err == nil {
+		if affected, err := result.RowsAffected(); err == nil {
+			span.SetAttributes(attribute.Int64("db.response.affected_rows", affected))
+		}
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// Rows counting the rows read, the span ending when they are closed
+type tracedRows struct {
+	RowsInterface
+	span  trace.Span
+	count int64
+	ended bool
+}
+
+func (r *tracedRows) Next() bool {
+	next := r.RowsInterface.Next()
+	if next {
+		r.count++
+	}
+	return next
+}
+
+func (r *tracedRows) Close() error {
+	err := r.RowsInterface.Close()
+	if !r.ended {
+		r.ended = true
+		r.span.SetAttributes(attribute.Int64("db.response.returned_rows", r.count))
+		endSpan(r.span, r.RowsInterface.Err())
+	}
+	return err
+}
+
+// Row whose span ends when it is scanned
+type tracedRow struct {
+	RowInterface
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	err := r.RowInterface.Scan(dest...)
+	returned, spanErr := int64(1), err
+	if errors.Is(err, sql.ErrNoRows) {
+		returned, spanErr = 0, nil
+	}
+	r.span.SetAttributes(attribute.Int64("db.response.returned_rows", returned))
+	endSpan(r.span, spanErr)
+	return err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
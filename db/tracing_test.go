@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedDB(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	remaining := 2
+	mockDB := &MockDB{
+		queryFunc: func(query string, args ...interface{}) (RowsInterface, error) {
+			return &MockRows{
+				nextFunc:  func() bool { remaining--; return remaining >= 0 },
+				scanFunc:  func(dest ...interface{}) error { return nil },
+				closeFunc: func() error { return nil },
+				errFunc:   func() error { return nil },
+			}, nil
+		},
+		queryRowFunc: func(query string, args ...interface{}) RowInterface {
+			return &MockRow{scanFunc: func(dest ...interface{}) error { return sql.ErrNoRows }}
+		},
+		execFunc: func(query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 3}, nil
+		},
+	}
+	traced := NewTracedDB(mockDB, "sqlite")
+
+	rows, _ := traced.QueryContext(context.Background(), "SELECT name\n  FROM skill")
+	for rows.Next() {
+	}
+	rows.Close()
+	rows.Close()
+
+	if err := traced.QueryRowContext(context.Background(), "SELECT id FROM profile WHERE id = ?", 1).Scan(); err != sql.ErrNoRows {
+		t.Errorf("expected the row error to be returned, got %v", err)
+	}
+
+	traced.ExecContext(context.Background(), "UPDATE api_key SET revoked_at = ?", 1)
+
+	stmt, err := traced.PrepareContext(context.Background(), "DELETE FROM skill WHERE id = ?")
+	if err != nil {
+		t.Fatalf("PrepareContext() = %v", err)
+	}
+	stmt.ExecContext(context.Background(), 1)
+	stmt.Close()
+	if !mockDB.stmts[0].closed {
+		t.Error("expected the prepared statement to be closed")
+	}
+
+	tests := []struct {
+		name       string
+		statement  string
+		attribute  attribute.KeyValue
+		wantStatus codes.Code
+	}{
+		{
+			name:      "SELECT",
+			statement: "SELECT name FROM skill",
+			attribute: attribute.Int64("db.response.returned_rows", 2),
+		},
+		{
+			name:      "SELECT",
+			statement: "SELECT id FROM profile WHERE id = ?",
+			attribute: attribute.Int64("db.response.returned_rows", 0),
+		},
+		{
+			name:      "UPDATE",
+			statement: "UPDATE api_key SET revoked_at = ?",
+			attribute: attribute.Int64("db.response.affected_rows", 3),
+		},
+		{
+			name:      "DELETE",
+			statement: "DELETE FROM skill WHERE id = ?",
+			attribute: attribute.Int64("db.response.affected_rows", 3),
+		},
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != len(tests) {
+		t.Fatalf("expected %d spans, got %d", len(tests), len(spans))
+	}
+
+	for i, tt := range tests {
+		span := spans[i]
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+
+		if span.Name() != tt.name || attrs["db.query.text"].AsString() != tt.statement {
+			t.Errorf("unexpected span %s with statement %q", span.Name(), attrs["db.query.text"].AsString())
+		}
+
+		if attrs[tt.attribute.Key] != tt.attribute.Value {
+			t.Errorf("expected %v, got %v", tt.attribute, attrs[tt.attribute.Key])
+		}
+
+		if span.Status().Code != tt.wantStatus {
+			t.Errorf("expected status %v, got %v", tt.wantStatus, span.Status())
+		}
+	}
+}
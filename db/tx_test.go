@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flmailla/resume/models"
+)
+
+func TestWithTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name           string
+		fn             func(tx *Store) error
+		beginErr       error
+		commitErr      error
+		wantErr        error
+		wantPanic      bool
+		wantCommitted  bool
+		wantRolledBack bool
+		wantStatements []string
+	}{
+		{
+			name: "committed on success",
+			fn: func(tx *Store) error {
+				return tx.LinkSkillToExperience(context.Background(), 1, 2)
+			},
+			wantCommitted:  true,
+			wantStatements: []string{"INSERT INTO skill_experience (experience_id, skill_id) VALUES (?, ?)"},
+		},
+		{
+			name: "rolled back on error",
+			fn: func(tx *Store) error {
+				return errFailed
+			},
+			wantErr:        errFailed,
+			wantRolledBack: true,
+		},
+		{
+			name: "rolled back on panic",
+			fn: func(tx *Store) error {
+				panic("boom")
+			},
+			wantPanic:      true,
+			wantRolledBack: true,
+		},
+		{
+			name:     "begin failure",
+			fn:       func(tx *Store) error { return nil },
+			beginErr: errFailed,
+			wantErr:  errFailed,
+		},
+		{
+			name:          "commit failure",
+			fn:            func(tx *Store) error { return nil },
+			commitErr:     errFailed,
+			wantErr:       errFailed,
+			wantCommitted: true,
+		},
+		{
+			name: "nested unit of work released",
+			fn: func(tx *Store) error {
+				return tx.WithTx(context.Background(), func(nested *Store) error {
+					return nested.WithTx(context.Background(), func(*Store) error { return nil })
+				})
+			},
+			wantCommitted:  true,
+			wantStatements: []string{"SAVEPOINT sp_1", "SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1"},
+		},
+		{
+			name: "nested failure rolled back to its savepoint only",
+			fn: func(tx *Store) error {
+				err := tx.WithTx(context.Background(), func(*Store) error { return errFailed })
+				if !errors.Is(err, errFailed) {
+					return errors.New("expected the nested error")
+				}
+				return nil
+			},
+			wantCommitted:  true,
+			wantStatements: []string{"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1"},
+		},
+		{
+			name: "nested panic rolls back everything",
+			fn: func(tx *Store) error {
+				return tx.WithTx(context.Background(), func(*Store) error { panic("boom") })
+			},
+			wantPanic:      true,
+			wantRolledBack: true,
+			wantStatements: []string{"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &MockTx{commitErr: tt.commitErr}
+			store := NewStore(&MockDB{
+				beginFunc: func() (TxInterface, error) {
+					if tt.beginErr != nil {
+						return nil, tt.beginErr
+					}
+					return tx, nil
+				},
+			})
+
+			var err error
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				err = store.WithTx(context.Background(), tt.fn)
+				return false
+			}()
+
+			if panicked != tt.wantPanic {
+				t.Errorf("panicked = %v, want %v", panicked, tt.wantPanic)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tx.committed != tt.wantCommitted || tx.rolledBack != tt.wantRolledBack {
+				t.Errorf("committed = %v, rolled back = %v, want %v and %v", tx.committed, tx.rolledBack, tt.wantCommitted, tt.wantRolledBack)
+			}
+			if !reflect.DeepEqual(tx.statements, tt.wantStatements) {
+				t.Errorf("statements = %q, want %q", tx.statements, tt.wantStatements)
+			}
+		})
+	}
+}
+
+func TestCreateExperienceIsAtomic(t *testing.T) {
+	tx := &MockTx{}
+	tx.queryRowFunc = func(query string, args ...interface{}) RowInterface {
+		return &MockRow{scanFunc: func(dest ...interface{}) error {
+			*dest[0].(*int64) = 7
+			return nil
+		}}
+	}
+	tx.execFunc = func(query string, args ...interface{}) (sql.Result, error) {
+		if len(tx.statements) == 2 {
+			return nil, models.ErrDBRequestFailed
+		}
+		return MockResult{}, nil
+	}
+	store := NewStore(&MockDB{beginFunc: func() (TxInterface, error) { return tx, nil }})
+
+	_, err := store.CreateExperience(context.Background(), 1, models.Experience{
+		Title:  "Integration expert",
+		Skills: []models.Skill{{ID: 1}, {ID: 2}, {ID: 3}},
+	})
+
+	if !errors.Is(err, models.ErrDBRequestFailed) {
+		t.Errorf("Error = %v, wantErr %v", err, models.ErrDBRequestFailed)
+	}
+	if tx.committed || !tx.rolledBack {
+		t.Error("expected the experience and its first links to be rolled back")
+	}
+}
+
+// Savepoints as understood by SQLite
+func TestWithTxSQLite(t *testing.T) {
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("CREATE TABLE skill_experience (experience_id INTEGER, skill_id INTEGER)"); err != nil {
+		t.Fatalf("failed to create the table: %v", err)
+	}
+
+	ctx := context.Background()
+	store := NewStoreFromSQLDB(conn, 0)
+
+	err = store.WithTx(ctx, func(tx *Store) error {
+		if err := tx.LinkSkillToExperience(ctx, 1, 1); err != nil {
+			return err
+		}
+		tx.WithTx(ctx, func(nested *Store) error {
+			nested.LinkSkillToExperience(ctx, 1, 2)
+			return errors.New("discarded")
+		})
+		return tx.WithTx(ctx, func(nested *Store) error {
+			return nested.LinkSkillToExperience(ctx, 1, 3)
+		})
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	store.WithTx(ctx, func(tx *Store) error {
+		tx.LinkSkillToExperience(ctx, 2, 1)
+		return errors.New("discarded")
+	})
+
+	var skills []int64
+	rows, err := conn.Query("SELECT skill_id FROM skill_experience ORDER BY experience_id, skill_id")
+	if err != nil {
+		t.Fatalf("failed to read the links: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var skill int64
+		rows.Scan(&skill)
+		skills = append(skills, skill)
+	}
+
+	if !reflect.DeepEqual(skills, []int64{1, 3}) {
+		t.Errorf("expected the links 1 and 3 to be committed, got %v", skills)
+	}
+}
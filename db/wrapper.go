@@ -1,18 +1,156 @@
 package db
 
-func (db *DBWrapper) Query(query string, args ...interface{}) (RowsInterface, error) {
-	rows, err := db.db.Query(query, args...)
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+func (db *DBWrapper) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	rows, err := db.pool(query).QueryContext(ctx, db.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
 	return rows, nil
 }
 
-func (db *DBWrapper) QueryRow(query string, args ...interface{}) RowInterface {
-	row := db.db.QueryRow(query, args...)
+func (db *DBWrapper) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	row := db.pool(query).QueryRowContext(ctx, db.rebind(query), args...)
 	return row
 }
 
+// Pool running a statement, the read pool taking the SELECT ones.
+// INSERT ... RETURNING statements are queried too, and stay on the write pool.
+func (db *DBWrapper) pool(query string) *sql.DB {
+	if db.read != nil && isSelect(query) {
+		return db.read
+	}
+	return db.db
+}
+
+func isSelect(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) >= 6 && strings.EqualFold(query[:6], "SELECT")
+}
+
+func (db *DBWrapper) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.db.ExecContext(ctx, db.rebind(query), args...)
+}
+
+func (db *DBWrapper) BeginTx(ctx context.Context, opts *sql.TxOptions) (TxInterface, error) {
+	tx, err := db.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &TxWrapper{tx: tx, rebind: db.rebind}, nil
+}
+
+// Prepare a statement on the pool running it. The statement is prepared
+// again by database/sql on every connection of the pool it runs on.
+func (db *DBWrapper) PrepareContext(ctx context.Context, query string) (StmtInterface, error) {
+	stmt, err := db.pool(query).PrepareContext(ctx, db.rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &StmtWrapper{stmt: stmt}, nil
+}
+
 func (db *DBWrapper) Close() error {
 	return db.db.Close()
 }
+
+// Concrete implementation that wraps sql.Stmt
+type StmtWrapper struct {
+	stmt *sql.Stmt
+}
+
+func (s *StmtWrapper) QueryContext(ctx context.Context, args ...interface{}) (RowsInterface, error) {
+	rows, err := s.stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *StmtWrapper) QueryRowContext(ctx context.Context, args ...interface{}) RowInterface {
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+func (s *StmtWrapper) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	return s.stmt.ExecContext(ctx, args...)
+}
+
+func (s *StmtWrapper) Close() error {
+	return s.stmt.Close()
+}
+
+// Statement run through a Querier, as the decorators of the statements
+// of a database are applied to its prepared statements as well
+type querierStmt struct {
+	q     Querier
+	query string
+	stmt  StmtInterface
+}
+
+func (s *querierStmt) QueryContext(ctx context.Context, args ...interface{}) (RowsInterface, error) {
+	return s.q.QueryContext(ctx, s.query, args...)
+}
+
+func (s *querierStmt) QueryRowContext(ctx context.Context, args ...interface{}) RowInterface {
+	return s.q.QueryRowContext(ctx, s.query, args...)
+}
+
+func (s *querierStmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	return s.q.ExecContext(ctx, s.query, args...)
+}
+
+func (s *querierStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// Querier running a prepared statement, whatever the query it is given
+type stmtQuerier struct {
+	stmt StmtInterface
+}
+
+func (q stmtQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	return q.stmt.QueryContext(ctx, args...)
+}
+
+func (q stmtQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	return q.stmt.QueryRowContext(ctx, args...)
+}
+
+func (q stmtQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return q.stmt.ExecContext(ctx, args...)
+}
+
+// Concrete implementation that wraps sql.Tx
+type TxWrapper struct {
+	tx     *sql.Tx
+	rebind func(query string) string
+}
+
+func (tx *TxWrapper) QueryContext(ctx context.Context, query string, args ...interface{}) (RowsInterface, error) {
+	rows, err := tx.tx.QueryContext(ctx, tx.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (tx *TxWrapper) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowInterface {
+	return tx.tx.QueryRowContext(ctx, tx.rebind(query), args...)
+}
+
+func (tx *TxWrapper) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.tx.ExecContext(ctx, tx.rebind(query), args...)
+}
+
+func (tx *TxWrapper) Commit() error {
+	return tx.tx.Commit()
+}
+
+func (tx *TxWrapper) Rollback() error {
+	return tx.tx.Rollback()
+}
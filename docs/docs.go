@@ -18,8 +18,184 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/experience/{experience_id}/skills": {
+        "/admin/backup": {
             "get": {
+                "security": [
+                    {
+                        "OAuth2Application": [
+                            "admin"
+                        ]
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Stream a consistent snapshot of the SQLite database, taken while serving",
+                "produces": [
+                    "application/vnd.sqlite3"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Download a backup",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Application": [
+                            "admin"
+                        ]
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get the minimum level of the logged records",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get the log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.logLevel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "OAuth2Application": [
+                            "admin"
+                        ]
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Change the minimum level of the logged records until the next restart",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Change the log level",
+                "parameters": [
+                    {
+                        "description": "debug, info, warn or error",
+                        "name": "level",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.logLevel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.logLevel"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    }
+                }
+            }
+        },
+        "/experiences/{experience_id}/skills": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
                 "description": "Retrieve all the skills for a given experience",
                 "consumes": [
                     "application/json"
@@ -51,19 +227,94 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Get the health status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Get a status about the service",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.responseStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.responseStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Report that the process is up, whatever its dependencies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.responseStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Check the dependencies of the service, 503 when one of them is down or the service is shutting down",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.readinessStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.readinessStatus"
                         }
                     }
                 }
@@ -74,6 +325,12 @@ const docTemplate = `{
                 "security": [
                     {
                         "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
                     }
                 ],
                 "description": "Retrieve the profil information",
@@ -106,19 +363,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -129,6 +386,12 @@ const docTemplate = `{
                 "security": [
                     {
                         "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
                     }
                 ],
                 "description": "Retrieve all the education lines for a given profile",
@@ -162,19 +425,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -185,6 +448,12 @@ const docTemplate = `{
                 "security": [
                     {
                         "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
                     }
                 ],
                 "description": "Retrieve all the experiences for a given profile",
@@ -218,19 +487,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -241,6 +510,12 @@ const docTemplate = `{
                 "security": [
                     {
                         "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
                     }
                 ],
                 "description": "Retrieve all the Licences for a given profile",
@@ -274,19 +549,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -294,6 +569,17 @@ const docTemplate = `{
         },
         "/profiles/{profile_id}/skills": {
             "get": {
+                "security": [
+                    {
+                        "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
                 "description": "Retrieve all the skills for a given profile",
                 "consumes": [
                     "application/json"
@@ -325,19 +611,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -345,6 +631,17 @@ const docTemplate = `{
         },
         "/skills": {
             "get": {
+                "security": [
+                    {
+                        "OAuth2Application": []
+                    },
+                    {
+                        "BearerToken": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
                 "description": "Retrieve all the skills in the database",
                 "consumes": [
                     "application/json"
@@ -366,19 +663,19 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponsNotFounde"
+                            "$ref": "#/definitions/ErrorResponsNotFound"
                         }
                     }
                 }
@@ -386,7 +683,7 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "ErrorResponsNotFounde": {
+        "ErrorResponsNotFound": {
             "type": "object",
             "properties": {
                 "code": {
@@ -403,6 +700,53 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.checkResult": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "handlers.logLevel": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "handlers.readinessStatus": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.checkResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "handlers.responseStatus": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Education": {
             "type": "object",
             "properties": {
@@ -490,7 +834,7 @@ const docTemplate = `{
         "models.LicenceType": {
             "type": "string",
             "enum": [
-                "Licnce",
+                "Licence",
                 "Certification"
             ],
             "x-enum-varnames": [
@@ -527,8 +871,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "postalCode": {
-                    "type": "integer",
-                    "format": "int32"
+                    "type": "integer"
                 },
                 "pronoun": {
                     "type": "string"
@@ -551,10 +894,21 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "APIKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerToken": {
+            "description": "A token or an API key, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "OAuth2Application": {
             "type": "oauth2",
             "flow": "application",
-            "tokenUrl": "https://example.com/oauth/token",
+            "tokenUrl": "https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/oauth2/v2.0/token",
             "scopes": {
                 "admin": "Grants read and write access to administrative information",
                 "write": "Grants write access"
@@ -566,8 +920,8 @@ const docTemplate = `{
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0.0",
-	Host:             "localhost:8080",
-	BasePath:         "/resume/v1",
+	Host:             "",
+	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Resume API",
 	Description:      "Displays my resume main sections as APIs",
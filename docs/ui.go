@@ -0,0 +1,37 @@
+package docs
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+//go:embed ui
+var ui embed.FS
+
+// Swagger UI loading the spec of /openapi.json, the index and the
+// initializer of ui/ taking over the ones of the Swagger UI distribution
+var UI fs.FS = overlay{top: mustSub(ui, "ui"), bottom: swaggerFiles.FS}
+
+// File system serving the files of top, and the ones of bottom it lacks
+type overlay struct {
+	top, bottom fs.FS
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.bottom.Open(name)
+	}
+	return f, err
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
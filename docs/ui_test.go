@@ -0,0 +1,23 @@
+package docs
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestUI(t *testing.T) {
+	initializer, err := fs.ReadFile(UI, "swagger-initializer.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(initializer), `"../openapi.json"`) {
+		t.Errorf("expected the initializer to load /openapi.json, got %s", initializer)
+	}
+	if _, err := fs.Stat(UI, "swagger-ui-bundle.js"); err != nil {
+		t.Errorf("expected the Swagger UI assets, got %v", err)
+	}
+	if _, err := UI.Open("missing.js"); err == nil {
+		t.Error("expected an unknown file to be missing")
+	}
+}
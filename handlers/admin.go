@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/logger"
+	"github.com/flmailla/resume/models"
+)
+
+// Scope required by the administrative endpoints
+const adminScope string = "admin"
+
+type AdminHandler struct {
+	// Consistent copy of the database, nil when the storage has none
+	snapshot func(ctx context.Context) (io.ReadCloser, error)
+}
+
+func NewAdminHandler(snapshot func(ctx context.Context) (io.ReadCloser, error)) *AdminHandler {
+	return &AdminHandler{snapshot: snapshot}
+}
+
+type logLevel struct {
+	Level string `json:"level" example:"debug"`
+}
+
+// @Summary Get the log level
+// @Description Get the minimum level of the logged records
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} logLevel
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security OAuth2Application[admin]
+// @Security BearerToken
+// @Security APIKey
+// @Router /admin/log-level [get]
+func (h *AdminHandler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, logLevel{Level: logger.Level()})
+}
+
+// @Summary Change the log level
+// @Description Change the minimum level of the logged records until the next restart
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param level body logLevel true "debug, info, warn or error"
+// @Success 200 {object} logLevel
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security OAuth2Application[admin]
+// @Security BearerToken
+// @Security APIKey
+// @Router /admin/log-level [put]
+func (h *AdminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var request logLevel
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, models.ErrorResponse{
+			Error:   models.ErrInvalidBody.Error(),
+			Code:    http.StatusBadRequest,
+			Message: "The body must be a JSON object with a level",
+		})
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(request.Level); err != nil {
+		writeJSON(w, http.StatusBadRequest, models.ErrorResponse{
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+			Message: "The level must be debug, info, warn or error",
+		})
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Log level changed", "log_level", logger.Level(), "previous_log_level", previous)
+	writeJSON(w, http.StatusOK, logLevel{Level: logger.Level()})
+}
+
+// @Summary Download a backup
+// @Description Stream a consistent snapshot of the SQLite database, taken while serving
+// @Tags Admin
+// @Produce application/vnd.sqlite3
+// @Success 200 {file} file
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 501 {object} models.ErrorResponse
+// @Security OAuth2Application[admin]
+// @Security BearerToken
+// @Security APIKey
+// @Router /admin/backup [get]
+func (h *AdminHandler) GetBackup(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	if h.snapshot == nil {
+		writeJSON(w, http.StatusNotImplemented, models.ErrorResponse{
+			Error:   models.ErrBackupUnsupported.Error(),
+			Code:    http.StatusNotImplemented,
+			Message: "Only the SQLite storage can be backed up",
+		})
+		return
+	}
+
+	snapshot, err := h.snapshot(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to snapshot the database", "error", err)
+		writeJSON(w, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+			Message: "The snapshot of the database failed",
+		})
+		return
+	}
+	defer snapshot.Close()
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="resume-%s.db"`, time.Now().UTC().Format("20060102T150405Z")))
+	if _, err := io.Copy(w, snapshot); err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to send the snapshot", "error", err)
+		return
+	}
+	logger.FromContext(r.Context()).Info("Database snapshot sent")
+}
+
+// Reject the callers without the admin scope, reporting whether the request may proceed
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	principal := auth.PrincipalFromContext(r.Context())
+	if principal != nil && principal.HasScope(adminScope) {
+		return true
+	}
+
+	writeJSON(w, http.StatusForbidden, models.ErrorResponse{
+		Error:   models.ErrForbidden.Error(),
+		Code:    http.StatusForbidden,
+		Message: "The admin scope is required",
+	})
+	return false
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/logger"
+)
+
+func TestLogLevel(t *testing.T) {
+	admin := &auth.Principal{Subject: "ops", Scopes: []string{"read", "admin"}}
+	reader := &auth.Principal{Subject: "app", Scopes: []string{"read"}}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		principal      *auth.Principal
+		wantStatusCode int
+		wantLevel      string
+	}{
+		{
+			name:           "Read the level",
+			method:         "GET",
+			principal:      admin,
+			wantStatusCode: http.StatusOK,
+			wantLevel:      "info",
+		},
+		{
+			name:           "Change the level",
+			method:         "PUT",
+			body:           `{"level":"DEBUG"}`,
+			principal:      admin,
+			wantStatusCode: http.StatusOK,
+			wantLevel:      "debug",
+		},
+		{
+			name:           "Unknown level",
+			method:         "PUT",
+			body:           `{"level":"verbose"}`,
+			principal:      admin,
+			wantStatusCode: http.StatusBadRequest,
+			wantLevel:      "info",
+		},
+		{
+			name:           "Malformed body",
+			method:         "PUT",
+			body:           `debug`,
+			principal:      admin,
+			wantStatusCode: http.StatusBadRequest,
+			wantLevel:      "info",
+		},
+		{
+			name:           "Missing admin scope",
+			method:         "PUT",
+			body:           `{"level":"debug"}`,
+			principal:      reader,
+			wantStatusCode: http.StatusForbidden,
+			wantLevel:      "info",
+		},
+		{
+			name:           "Anonymous caller",
+			method:         "GET",
+			wantStatusCode: http.StatusForbidden,
+			wantLevel:      "info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.SetLevel("info")
+			t.Cleanup(func() { logger.SetLevel("info") })
+
+			adminHandler := NewAdminHandler(nil)
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /admin/log-level", adminHandler.GetLogLevel)
+			mux.HandleFunc("PUT /admin/log-level", adminHandler.SetLogLevel)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/admin/log-level", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("expected status %d, got %d", tt.wantStatusCode, w.Code)
+			}
+
+			if got := logger.Level(); got != tt.wantLevel {
+				t.Errorf("expected level %q, got %q", tt.wantLevel, got)
+			}
+
+			if w.Code == http.StatusOK {
+				var got logLevel
+				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatalf("failed to unmarshal response body: %v", err)
+				}
+				if got.Level != tt.wantLevel {
+					t.Errorf("expected level %q in the response, got %q", tt.wantLevel, got.Level)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBackup(t *testing.T) {
+	admin := &auth.Principal{Subject: "ops", Scopes: []string{"admin"}}
+	reader := &auth.Principal{Subject: "app", Scopes: []string{"read"}}
+	snapshot := func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("SQLite format 3")), nil
+	}
+
+	tests := []struct {
+		name           string
+		snapshot       func(ctx context.Context) (io.ReadCloser, error)
+		principal      *auth.Principal
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:           "Snapshot streamed",
+			snapshot:       snapshot,
+			principal:      admin,
+			wantStatusCode: http.StatusOK,
+			wantBody:       "SQLite format 3",
+		},
+		{
+			name:           "Missing admin scope",
+			snapshot:       snapshot,
+			principal:      reader,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "Storage without backups",
+			principal:      admin,
+			wantStatusCode: http.StatusNotImplemented,
+		},
+		{
+			name: "Snapshot failure",
+			snapshot: func(ctx context.Context) (io.ReadCloser, error) {
+				return nil, errors.New("disk full")
+			},
+			principal:      admin,
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/admin/backup", nil)
+			r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+
+			NewAdminHandler(tt.snapshot).GetBackup(w, r)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("expected status %d, got %d", tt.wantStatusCode, w.Code)
+			}
+			if tt.wantBody != "" {
+				if w.Body.String() != tt.wantBody {
+					t.Errorf("expected body %q, got %q", tt.wantBody, w.Body.String())
+				}
+				if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="resume-`) {
+					t.Errorf("expected an attachment, got %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type DocsHandler struct {
+	spec []byte
+	ui   http.Handler
+}
+
+// Serve the swagger spec and the interactive documentation of ui. The spec
+// is adapted to the server: without host nor scheme, clients using the ones
+// they reach it on, with the OAuth2 token URL of the embedded issuer when
+// tokenURL is set, and with the routes matching publicPatterns, served to
+// anonymous callers, not requiring credentials.
+func NewDocsHandler(spec string, ui fs.FS, tokenURL string, publicPatterns []string) (*DocsHandler, error) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(spec), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the spec: %w", err)
+	}
+
+	if host, _ := doc["host"].(string); host == "" {
+		delete(doc, "host")
+	}
+	if schemes, _ := doc["schemes"].([]any); len(schemes) == 0 {
+		delete(doc, "schemes")
+	}
+	if tokenURL != "" {
+		if definitions, ok := doc["securityDefinitions"].(map[string]any); ok {
+			for _, definition := range definitions {
+				if definition, ok := definition.(map[string]any); ok && definition["type"] == "oauth2" {
+					definition["tokenUrl"] = tokenURL
+				}
+			}
+		}
+	}
+	if len(publicPatterns) > 0 {
+		allowAnonymous(doc, publicPatterns)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the spec: %w", err)
+	}
+	return &DocsHandler{spec: data, ui: http.StripPrefix("/docs", http.FileServerFS(ui))}, nil
+}
+
+var pathParam = regexp.MustCompile(`\{[^}/]+\}`)
+
+// Make the credentials of the operations matching one of the patterns
+// optional, an empty security requirement standing for anonymous callers
+func allowAnonymous(doc map[string]any, patterns []string) {
+	public := http.NewServeMux()
+	registered := make(map[string]bool)
+	for _, pattern := range patterns {
+		if !registered[pattern] {
+			registered[pattern] = true
+			public.Handle(pattern, http.NotFoundHandler())
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	for path, operations := range paths {
+		operations, _ := operations.(map[string]any)
+		for method, operation := range operations {
+			operation, ok := operation.(map[string]any)
+			security, _ := operation["security"].([]any)
+			if !ok || len(security) == 0 {
+				continue
+			}
+			r, err := http.NewRequest(strings.ToUpper(method), pathParam.ReplaceAllString(path, "1"), nil)
+			if err != nil {
+				continue
+			}
+			if _, pattern := public.Handler(r); pattern != "" {
+				operation["security"] = append([]any{map[string]any{}}, security...)
+			}
+		}
+	}
+}
+
+// Serve the swagger spec
+func (h *DocsHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+// Serve the interactive documentation, /docs being redirected to /docs/
+func (h *DocsHandler) GetUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/docs" {
+		http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+		return
+	}
+	h.ui.ServeHTTP(w, r)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+const testSpec = `{
+    "swagger": "2.0",
+    "host": "",
+    "basePath": "/",
+    "schemes": [],
+    "paths": {
+        "/profiles/{profile_id}": {"get": {"security": [{"OAuth2Application": []}]}},
+        "/skills": {"get": {"security": [{"OAuth2Application": []}]}},
+        "/health": {"get": {}}
+    },
+    "securityDefinitions": {
+        "OAuth2Application": {"type": "oauth2", "flow": "application", "tokenUrl": "https://login.example.com/token"},
+        "APIKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"}
+    }
+}`
+
+func TestDocsHandlerSpec(t *testing.T) {
+	handler, err := NewDocsHandler(testSpec, fstest.MapFS{}, "http://localhost:8090/oauth/token", []string{"GET /profiles/{profile_id}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetSpec(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected a JSON spec, got %s", rec.Header().Get("Content-Type"))
+	}
+
+	var spec struct {
+		Host                *string `json:"host"`
+		Schemes             []string
+		Paths               map[string]map[string]struct{ Security []map[string][]string }
+		SecurityDefinitions map[string]struct {
+			TokenURL string `json:"tokenUrl"`
+		}
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Host != nil || spec.Schemes != nil {
+		t.Errorf("expected no host nor scheme, got %v and %v", *spec.Host, spec.Schemes)
+	}
+	if got := spec.SecurityDefinitions["OAuth2Application"].TokenURL; got != "http://localhost:8090/oauth/token" {
+		t.Errorf("expected the token URL of the issuer, got %s", got)
+	}
+	if security := spec.Paths["/profiles/{profile_id}"]["get"].Security; len(security) != 2 || len(security[0]) != 0 {
+		t.Errorf("expected the public route to allow anonymous callers, got %v", security)
+	}
+	if security := spec.Paths["/skills"]["get"].Security; len(security) != 1 {
+		t.Errorf("expected the other routes to require credentials, got %v", security)
+	}
+	if security := spec.Paths["/health"]["get"].Security; security != nil {
+		t.Errorf("expected the unauthenticated routes to be left as is, got %v", security)
+	}
+}
+
+func TestDocsHandlerUI(t *testing.T) {
+	ui := fstest.MapFS{
+		"index.html":           {Data: []byte("<html>Resume API</html>")},
+		"swagger-ui-bundle.js": {Data: []byte("SwaggerUIBundle")},
+	}
+	handler, err := NewDocsHandler(testSpec, ui, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path         string
+		wantStatus   int
+		wantBody     string
+		wantLocation string
+	}{
+		{"/docs", http.StatusMovedPermanently, "", "/docs/"},
+		{"/docs/", http.StatusOK, "<html>Resume API</html>", ""},
+		{"/docs/swagger-ui-bundle.js", http.StatusOK, "SwaggerUIBundle", ""},
+		{"/docs/missing.js", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.GetUI(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.wantStatus, rec.Code)
+		}
+		if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.wantBody, rec.Body.String())
+		}
+		if got := rec.Header().Get("Location"); got != tt.wantLocation {
+			t.Errorf("%s: expected location %q, got %q", tt.path, tt.wantLocation, got)
+		}
+	}
+}
+
+func TestDocsHandlerInvalidSpec(t *testing.T) {
+	if _, err := NewDocsHandler("{", fstest.MapFS{}, "", nil); err == nil {
+		t.Error("expected an invalid spec to be refused")
+	}
+}
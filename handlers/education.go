@@ -29,19 +29,21 @@ func NewEducationHandler(store storeHandler) *EducationHandler {
 // @Router /profiles/{profile_id}/educations [get]
 // @Param profile_id path int true "Profile ID"
 // @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *EducationHandler) GetEducationsByProfile(w http.ResponseWriter, r *http.Request) {
 	profileId, err := strconv.Atoi(r.PathValue("profile_id"))
 	if err != nil {
-		logger.Logger.Error(err.Error())
+		logger.FromContext(r.Context()).Error(err.Error())
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
 		return
 	}
-	educations, err := h.store.GetDistinctEducationsByProfile(profileId)
+	educations, err := h.store.GetDistinctEducationsByProfile(r.Context(), profileId)
 	if err != nil {
-		logger.Logger.Error(err.Error())
+		logger.FromContext(r.Context()).Error(err.Error())
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrEducationsNotFetched.Error(), "detail": err.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, educations)
+	writeResponse(w, r, http.StatusOK, educations)
 }
@@ -29,21 +29,23 @@ func NewExperienceHandler(store storeHandler) *ExperienceHandler {
 // @Router /profiles/{profile_id}/experiences [get]
 // @Param profile_id path int true "Profile ID"
 // @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *ExperienceHandler) GetExperiencesByProfile(w http.ResponseWriter, r *http.Request) {
 
-	logger.Logger.Info("health endpoint requested")
+	logger.FromContext(r.Context()).Info("health endpoint requested")
 
 	profileId, err := strconv.Atoi(r.PathValue("profile_id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
-		logger.Logger.Warn("Experience endpoint", models.ErrInvalidId.Error(), profileId)
+		logger.FromContext(r.Context()).Warn("Experience endpoint", models.ErrInvalidId.Error(), profileId)
 		return
 	}
-	profile, err := h.store.GetDistinctExperiencesByProfile(profileId)
+	profile, err := h.store.GetDistinctExperiencesByProfile(r.Context(), profileId)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrExperiencesNotFetched.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, profile)
+	writeResponse(w, r, http.StatusOK, profile)
 }
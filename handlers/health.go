@@ -1,22 +1,74 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/flmailla/resume/logger"
 )
 
+// Longest time a readiness check may take
+const checkTimeout = 2 * time.Second
+
 type HealthHandler struct {
-	store storeHandler
+	checkers []Checker
+	draining atomic.Bool
+}
+
+// Dependency checked by the readiness probe
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// Build a checker from a function
+func NewCheck(name string, fn func(ctx context.Context) error) Checker {
+	return checkFunc{name: name, fn: fn}
+}
+
+// Result of a readiness check, its error being logged only as the
+// probe is served to anonymous callers
+type checkResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status" example:"up"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Readiness of the service and of its dependencies
+type readinessStatus struct {
+	Status string        `json:"status" example:"ready"`
+	Checks []checkResult `json:"checks"`
 }
 
 type responseStatus struct {
 	Status string
 }
 
-func NewHealthHandler(store storeHandler) *HealthHandler {
-	return &HealthHandler{store: store}
+func NewHealthHandler(checkers ...Checker) *HealthHandler {
+	return &HealthHandler{checkers: checkers}
+}
+
+// Report the service as not ready, so that no new traffic is routed
+// to it while the in-flight requests are drained
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
 }
 
 // @Summary Get a status about the service
@@ -24,19 +76,87 @@ func NewHealthHandler(store storeHandler) *HealthHandler {
 // @Tags Health
 // @Accept json
 // @Produce json
-// @Success 200 {object} models.Licence
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 403 {object} models.ErrorResponse
-// @Failure 404 {object} models.ErrorResponse
+// @Success 200 {object} responseStatus
+// @Failure 503 {object} responseStatus
 // @Router /health [get]
 func (h *HealthHandler) GetHealthStatus(w http.ResponseWriter, r *http.Request) {
 
-	logger.Logger.Info("health endpoint requested")
+	logger.FromContext(r.Context()).Info("health endpoint requested")
+
+	status, code := "healthy", http.StatusOK
+	if h.draining.Load() {
+		status, code = "draining", http.StatusServiceUnavailable
+	}
 
 	response := map[string]string{
-		"Status": "healthy",
+		"Status": status,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(response)
 }
+
+// @Summary Liveness probe
+// @Description Report that the process is up, whatever its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} responseStatus
+// @Router /health/live [get]
+func (h *HealthHandler) GetLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"Status": "alive"})
+}
+
+// @Summary Readiness probe
+// @Description Check the dependencies of the service, 503 when one of them is down or the service is shutting down
+// @Tags Health
+// @Produce json
+// @Success 200 {object} readinessStatus
+// @Failure 503 {object} readinessStatus
+// @Router /health/ready [get]
+func (h *HealthHandler) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, readinessStatus{Status: "draining", Checks: []checkResult{}})
+		return
+	}
+
+	results := h.runChecks(r.Context())
+
+	response, code := readinessStatus{Status: "ready", Checks: results}, http.StatusOK
+	for _, result := range results {
+		if result.Status != "up" {
+			response.Status, code = "degraded", http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, code, response)
+}
+
+// Run the checks concurrently, in the registration order in the results
+func (h *HealthHandler) runChecks(ctx context.Context) []checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]checkResult, len(h.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range h.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := checker.Check(ctx)
+			results[i] = checkResult{
+				Name:       checker.Name(),
+				Status:     "up",
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = "down"
+				logger.FromContext(ctx).Warn("Readiness check failed", "check", checker.Name(), "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
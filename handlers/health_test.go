@@ -1,30 +1,43 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetHealth(t *testing.T) {
 	tests := []struct {
 		name              string
-		mockStore         *mockStore
+		draining          bool
 		wantStatusCode    int
 		wantStatusMessage string
 	}{
 		{
 			name:              "Retrieve status",
-			mockStore:         nil,
 			wantStatusCode:    http.StatusOK,
 			wantStatusMessage: "healthy",
 		},
+		{
+			name:              "Draining before shutdown",
+			draining:          true,
+			wantStatusCode:    http.StatusServiceUnavailable,
+			wantStatusMessage: "draining",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			healthHandler := NewHealthHandler(tt.mockStore)
+			healthHandler := NewHealthHandler()
+			if tt.draining {
+				healthHandler.SetDraining()
+			}
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("GET /health", healthHandler.GetHealthStatus)
@@ -51,3 +64,128 @@ func TestGetHealth(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLiveness(t *testing.T) {
+	healthHandler := NewHealthHandler(NewCheck("database", func(ctx context.Context) error {
+		return errors.New("database is locked")
+	}))
+	healthHandler.SetDraining()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health/live", healthHandler.GetLiveness)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/health/live", nil)
+
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var got responseStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response body: %v", err)
+	}
+	if got.Status != "alive" {
+		t.Errorf("expected status %q, got %q", "alive", got.Status)
+	}
+}
+
+func TestGetReadiness(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("database is locked") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name           string
+		checkers       []Checker
+		draining       bool
+		wantStatusCode int
+		wantStatus     string
+		wantChecks     map[string]string
+	}{
+		{
+			name:           "All checks up",
+			checkers:       []Checker{NewCheck("database", up), NewCheck("jwks", up)},
+			wantStatusCode: http.StatusOK,
+			wantStatus:     "ready",
+			wantChecks:     map[string]string{"database": "up", "jwks": "up"},
+		},
+		{
+			name:           "One check down",
+			checkers:       []Checker{NewCheck("database", down), NewCheck("jwks", up)},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     "degraded",
+			wantChecks:     map[string]string{"database": "down", "jwks": "up"},
+		},
+		{
+			name:           "No checks",
+			wantStatusCode: http.StatusOK,
+			wantStatus:     "ready",
+			wantChecks:     map[string]string{},
+		},
+		{
+			name:           "Draining before shutdown",
+			checkers:       []Checker{NewCheck("database", up)},
+			draining:       true,
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     "draining",
+			wantChecks:     map[string]string{},
+		},
+		{
+			name:           "Check cancelled by the client",
+			checkers:       []Checker{NewCheck("jwks", slow)},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     "degraded",
+			wantChecks:     map[string]string{"jwks": "down"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthHandler := NewHealthHandler(tt.checkers...)
+			if tt.draining {
+				healthHandler.SetDraining()
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /health/ready", healthHandler.GetReadiness)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/health/ready", nil).WithContext(ctx)
+
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("expected status %d, got %d", tt.wantStatusCode, w.Code)
+			}
+
+			var got readinessStatus
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response body: %v", err)
+			}
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, got.Status)
+			}
+
+			checks := make(map[string]string)
+			for _, check := range got.Checks {
+				checks[check.Name] = check.Status
+			}
+			if strings.Contains(w.Body.String(), "locked") || strings.Contains(w.Body.String(), "deadline") {
+				t.Errorf("expected the errors to be kept out of the response, got %s", w.Body.String())
+			}
+			if !reflect.DeepEqual(checks, tt.wantChecks) {
+				t.Errorf("expected checks %v, got %v", tt.wantChecks, checks)
+			}
+		})
+	}
+}
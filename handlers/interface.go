@@ -1,15 +1,17 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/flmailla/resume/models"
 )
 
 type storeHandler interface {
-	GetDistinctEducationsByProfile(profileId int) ([]models.Education, error)
-	GetDistinctExperiencesByProfile(profileId int) ([]models.Experience, error)
-	GetDistinctLicencesByProfile(profileId int) ([]models.Licence, error)
-	GetProfileById(profileId int) (*models.Profile, error)
-	GetDistinctSkills() ([]models.Skill, error)
-	GetDistinctSkillsByProfile(profileId int) ([]models.Skill, error)
-	GetDistinctSkillsByExperience(experienceId int) ([]models.Skill, error)
+	GetDistinctEducationsByProfile(ctx context.Context, profileId int) ([]models.Education, error)
+	GetDistinctExperiencesByProfile(ctx context.Context, profileId int) ([]models.Experience, error)
+	GetDistinctLicencesByProfile(ctx context.Context, profileId int) ([]models.Licence, error)
+	GetProfileById(ctx context.Context, profileId int) (*models.Profile, error)
+	GetDistinctSkills(ctx context.Context) ([]models.Skill, error)
+	GetDistinctSkillsByProfile(ctx context.Context, profileId int) ([]models.Skill, error)
+	GetDistinctSkillsByExperience(ctx context.Context, experienceId int) ([]models.Skill, error)
 }
@@ -29,18 +29,20 @@ func NewLicenceHandler(store storeHandler) *LicenceHandler {
 // @Param profile_id path int true "Profile ID"
 // @Router /profiles/{profile_id}/licences [get]
 // @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *LicenceHandler) GetLicencesByProfile(w http.ResponseWriter, r *http.Request) {
 	profileId, err := strconv.Atoi(r.PathValue("profile_id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
-		logger.Logger.Warn("Licence endpoint", models.ErrInvalidId.Error(), profileId)
+		logger.FromContext(r.Context()).Warn("Licence endpoint", models.ErrInvalidId.Error(), profileId)
 		return
 	}
-	licences, err := h.store.GetDistinctLicencesByProfile(profileId)
+	licences, err := h.store.GetDistinctLicencesByProfile(r.Context(), profileId)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrLicencesNotFetched.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, licences)
+	writeResponse(w, r, http.StatusOK, licences)
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/flmailla/resume/models"
 )
 
@@ -12,51 +14,59 @@ type mockStore struct {
 	GetDistinctSkillsFunc               func() ([]models.Skill, error)
 	GetDistinctSkillsByProfileFunc      func(profileId int) ([]models.Skill, error)
 	GetDistinctSkillsByExperienceFunc   func(experienceId int) ([]models.Skill, error)
+	ctx                                 context.Context // context of the last call
 }
 
-func (m *mockStore) GetDistinctEducationsByProfile(profileId int) ([]models.Education, error) {
+func (m *mockStore) GetDistinctEducationsByProfile(ctx context.Context, profileId int) ([]models.Education, error) {
+	m.ctx = ctx
 	if m.GetDistinctEducationsByProfileFunc != nil {
 		return m.GetDistinctEducationsByProfileFunc(profileId)
 	}
 	return nil, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetDistinctExperiencesByProfile(profileId int) ([]models.Experience, error) {
+func (m *mockStore) GetDistinctExperiencesByProfile(ctx context.Context, profileId int) ([]models.Experience, error) {
+	m.ctx = ctx
 	if m.GetDistinctExperiencesByProfileFunc != nil {
 		return m.GetDistinctExperiencesByProfileFunc(profileId)
 	}
 	return nil, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetDistinctLicencesByProfile(profileId int) ([]models.Licence, error) {
+func (m *mockStore) GetDistinctLicencesByProfile(ctx context.Context, profileId int) ([]models.Licence, error) {
+	m.ctx = ctx
 	if m.GetDistinctLicencesByProfileFunc != nil {
 		return m.GetDistinctLicencesByProfileFunc(profileId)
 	}
 	return nil, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetProfileById(profileId int) (*models.Profile, error) {
+func (m *mockStore) GetProfileById(ctx context.Context, profileId int) (*models.Profile, error) {
+	m.ctx = ctx
 	if m.GetProfileFunc != nil {
 		return m.GetProfileFunc(profileId)
 	}
 	return &models.Profile{}, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetDistinctSkills() ([]models.Skill, error) {
+func (m *mockStore) GetDistinctSkills(ctx context.Context) ([]models.Skill, error) {
+	m.ctx = ctx
 	if m.GetDistinctSkillsFunc != nil {
 		return m.GetDistinctSkillsFunc()
 	}
 	return nil, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetDistinctSkillsByProfile(profileId int) ([]models.Skill, error) {
+func (m *mockStore) GetDistinctSkillsByProfile(ctx context.Context, profileId int) ([]models.Skill, error) {
+	m.ctx = ctx
 	if m.GetDistinctSkillsByProfileFunc != nil {
 		return m.GetDistinctSkillsByProfileFunc(profileId)
 	}
 	return nil, models.ErrNotImplemented
 }
 
-func (m *mockStore) GetDistinctSkillsByExperience(experienceId int) ([]models.Skill, error) {
+func (m *mockStore) GetDistinctSkillsByExperience(ctx context.Context, experienceId int) ([]models.Skill, error) {
+	m.ctx = ctx
 	if m.GetDistinctSkillsByExperienceFunc != nil {
 		return m.GetDistinctSkillsByExperienceFunc(experienceId)
 	}
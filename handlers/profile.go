@@ -28,18 +28,20 @@ func NewProfileHandler(store storeHandler) *ProfileHandler {
 // @Param profile_id path int true "Profile ID"
 // @Router /profiles/{profile_id} [get]
 // @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	profileId, err := strconv.Atoi(r.PathValue("profile_id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
-		logger.Logger.Warn("Profile endpoint", models.ErrInvalidId.Error(), profileId)
+		logger.FromContext(r.Context()).Warn("Profile endpoint", models.ErrInvalidId.Error(), profileId)
 		return
 	}
-	profile, err := h.store.GetProfileById(profileId)
+	profile, err := h.store.GetProfileById(r.Context(), profileId)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrProfileNotFetched.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, profile)
+	writeResponse(w, r, http.StatusOK, profile)
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flmailla/resume/internal/auth"
 	"github.com/flmailla/resume/models"
 )
 
@@ -75,6 +76,7 @@ func TestGetProfile(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("GET", "/profiles/1", nil)
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Subject: "test"}))
 
 			mux.ServeHTTP(w, r)
 
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/models"
+)
+
+func TestRedactAnonymous(t *testing.T) {
+	profile := &models.Profile{
+		ID:         1,
+		FirstName:  "FN1",
+		BirthDate:  time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC),
+		Email:      "email@maillard.ch",
+		PostalCode: 1000,
+	}
+
+	tests := []struct {
+		name        string
+		payload     any
+		wantKeys    []string
+		missingKeys []string
+	}{
+		{
+			name:        "profile",
+			payload:     profile,
+			wantKeys:    []string{"ID", "FirstName", "LastName"},
+			missingKeys: []string{"Email", "BirthDate", "PostalCode"},
+		},
+		{
+			name:        "nested profile",
+			payload:     models.Experience{ID: 1, Profile: *profile, StartDate: time.Now()},
+			wantKeys:    []string{"ID", "StartDate", "Profile"},
+			missingKeys: []string{},
+		},
+		{
+			name:     "json tags are honoured",
+			payload:  models.Skill{ID: 1, Name: "git"},
+			wantKeys: []string{"id", "name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(redactAnonymous(tt.payload))
+			if err != nil {
+				t.Fatalf("failed to marshal redacted payload: %v", err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to unmarshal redacted payload: %v", err)
+			}
+
+			for _, key := range tt.wantKeys {
+				if _, exists := got[key]; !exists {
+					t.Errorf("expected key %s in %s", key, data)
+				}
+			}
+
+			for _, key := range tt.missingKeys {
+				if _, exists := got[key]; exists {
+					t.Errorf("expected key %s to be redacted in %s", key, data)
+				}
+			}
+
+			if nested, ok := got["Profile"].(map[string]any); ok {
+				if _, exists := nested["Email"]; exists {
+					t.Errorf("expected nested Email to be redacted in %s", data)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactAnonymousKeepsTimes(t *testing.T) {
+	birthDate := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+	payload := []models.Experience{{StartDate: birthDate}}
+
+	data, err := json.Marshal(redactAnonymous(payload))
+	if err != nil {
+		t.Fatalf("failed to marshal redacted payload: %v", err)
+	}
+
+	var got []models.Experience
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal redacted payload: %v", err)
+	}
+
+	if !got[0].StartDate.Equal(birthDate) {
+		t.Errorf("expected start date %v, got %v", birthDate, got[0].StartDate)
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	profile := &models.Profile{ID: 1, Email: "email@maillard.ch"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantEmail string
+	}{
+		{
+			name:      "authenticated caller",
+			principal: &auth.Principal{Subject: "client"},
+			wantEmail: "email@maillard.ch",
+		},
+		{
+			name:      "anonymous caller",
+			principal: nil,
+			wantEmail: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/profiles/1", nil)
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			writeResponse(w, r, http.StatusOK, profile)
+
+			var got models.Profile
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response body: %v", err)
+			}
+
+			if got.Email != tt.wantEmail {
+				t.Errorf("expected email %q, got %q", tt.wantEmail, got.Email)
+			}
+		})
+	}
+}
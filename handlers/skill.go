@@ -26,15 +26,18 @@ func NewSkillHandler(store storeHandler) *SkillHandler {
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /skills [get]
+// @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *SkillHandler) GetSkills(w http.ResponseWriter, r *http.Request) {
-	profile, err := h.store.GetDistinctSkills()
+	profile, err := h.store.GetDistinctSkills(r.Context())
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrSkillsNotFetched.Error()})
-		logger.Logger.Warn(err.Error())
+		logger.FromContext(r.Context()).Warn(err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, profile)
+	writeResponse(w, r, http.StatusOK, profile)
 }
 
 // @Summary Get a profile skills
@@ -49,20 +52,23 @@ func (h *SkillHandler) GetSkills(w http.ResponseWriter, r *http.Request) {
 // @Failure 404 {object} models.ErrorResponse
 // @Param profile_id path int true "Profile ID"
 // @Router /profiles/{profile_id}/skills [get]
+// @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *SkillHandler) GetSkillsByProfile(w http.ResponseWriter, r *http.Request) {
 	profileId, err := strconv.Atoi(r.PathValue("profile_id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
-		logger.Logger.Warn("Skill endpoint", models.ErrInvalidId.Error(), profileId)
+		logger.FromContext(r.Context()).Warn("Skill endpoint", models.ErrInvalidId.Error(), profileId)
 		return
 	}
-	skills, err := h.store.GetDistinctSkillsByProfile(profileId)
+	skills, err := h.store.GetDistinctSkillsByProfile(r.Context(), profileId)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrSkillsNotFetched.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, skills)
+	writeResponse(w, r, http.StatusOK, skills)
 }
 
 // @Summary Get the experience skills
@@ -76,19 +82,22 @@ func (h *SkillHandler) GetSkillsByProfile(w http.ResponseWriter, r *http.Request
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Param experience_id path int true "Experience ID"
-// @Router /experience/{experience_id}/skills [get]
+// @Router /experiences/{experience_id}/skills [get]
+// @Security OAuth2Application
+// @Security BearerToken
+// @Security APIKey
 func (h *SkillHandler) GetSkillsByExperience(w http.ResponseWriter, r *http.Request) {
 	experienceId, err := strconv.Atoi(r.PathValue("experience_id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": models.ErrInvalidId.Error()})
-		logger.Logger.Warn("Skill endpoint", models.ErrInvalidId.Error(), experienceId)
+		logger.FromContext(r.Context()).Warn("Skill endpoint", models.ErrInvalidId.Error(), experienceId)
 		return
 	}
-	experiences, err := h.store.GetDistinctSkillsByExperience(experienceId)
+	experiences, err := h.store.GetDistinctSkillsByExperience(r.Context(), experienceId)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": models.ErrSkillsNotFetched.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, experiences)
+	writeResponse(w, r, http.StatusOK, experiences)
 }
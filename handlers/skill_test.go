@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -497,3 +498,21 @@ func TestSkillsEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSkillsUseRequestContext(t *testing.T) {
+	store := &mockStore{
+		GetDistinctSkillsFunc: func() ([]models.Skill, error) {
+			return []models.Skill{}, nil
+		},
+	}
+	skillHandler := NewSkillHandler(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/skills", nil).WithContext(ctx)
+	skillHandler.GetSkills(httptest.NewRecorder(), r)
+
+	cancel()
+	if store.ctx == nil || store.ctx.Err() == nil {
+		t.Error("expected the store to receive the request context, cancelled with the request")
+	}
+}
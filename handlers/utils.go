@@ -4,9 +4,18 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/flmailla/resume/internal/auth"
 	"github.com/flmailla/resume/logger"
 )
 
+// Write a JSON payload, redacted when the caller is anonymous
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, payload any) {
+	if auth.PrincipalFromContext(r.Context()) == nil {
+		payload = redactAnonymous(payload)
+	}
+	writeJSON(w, status, payload)
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+type mockAPIKeyStore struct {
+	keys    map[string]*models.APIKey
+	touched []int64
+	err     error
+}
+
+func (m *mockAPIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if key, exists := m.keys[hash]; exists {
+		return key, nil
+	}
+	return nil, models.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyStore) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		t.Errorf("expected key prefixed by %s, got %s", apiKeyPrefix, key)
+	}
+
+	if hash != HashSecret(key) {
+		t.Errorf("expected hash %s, got %s", HashSecret(key), hash)
+	}
+
+	other, _, _ := GenerateAPIKey()
+	if other == key {
+		t.Error("expected two generated keys to differ")
+	}
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	revokedAt := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+	lastUsedAt := time.Now().Add(-10 * time.Second)
+	store := &mockAPIKeyStore{keys: map[string]*models.APIKey{
+		HashSecret("rsk_valid"):   {ID: 1, Name: "batch", Scopes: []string{"read"}},
+		HashSecret("rsk_revoked"): {ID: 2, Name: "old", RevokedAt: &revokedAt},
+		HashSecret("rsk_recent"):  {ID: 3, Name: "sync", LastUsedAt: &lastUsedAt},
+	}}
+
+	tests := []struct {
+		name        string
+		header      string
+		value       string
+		wantSubject string
+		wantSkip    bool
+		wantErr     error
+	}{
+		{
+			name:        "valid bearer key",
+			header:      "Authorization",
+			value:       "Bearer rsk_valid",
+			wantSubject: "batch",
+		},
+		{
+			name:        "valid X-API-Key header",
+			header:      "X-API-Key",
+			value:       "rsk_valid",
+			wantSubject: "batch",
+		},
+		{
+			name:        "key used less than a minute ago",
+			header:      "X-API-Key",
+			value:       "rsk_recent",
+			wantSubject: "sync",
+		},
+		{
+			name:     "no credentials",
+			wantSkip: true,
+		},
+		{
+			name:     "bearer token which is not an api key",
+			header:   "Authorization",
+			value:    "Bearer eyJhbGciOi",
+			wantSkip: true,
+		},
+		{
+			name:    "unknown key",
+			header:  "Authorization",
+			value:   "Bearer rsk_unknown",
+			wantErr: models.ErrAPIKeyNotFound,
+		},
+		{
+			name:    "revoked key",
+			header:  "Authorization",
+			value:   "Bearer rsk_revoked",
+			wantErr: models.ErrAPIKeyRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewAPIKeyAuthenticator(store)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			principal, err := authenticator.Authenticate(req)
+
+			if tt.wantSkip {
+				if !errors.Is(err, ErrNoCredentials) {
+					t.Errorf("expected ErrNoCredentials, got %v", err)
+				}
+				return
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || errors.Is(err, ErrNoCredentials) {
+					t.Errorf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if principal.Subject != tt.wantSubject || principal.Method != "apikey" {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+		})
+	}
+
+	if len(store.touched) != 2 || slices.Contains(store.touched, 3) {
+		t.Errorf("expected 2 recorded usages of the key not used recently, got %v", store.touched)
+	}
+}
+
+func TestAPIKeyStoreFailure(t *testing.T) {
+	store := &mockAPIKeyStore{err: errors.New("database is locked")}
+	handler := Chain{NewAPIKeyAuthenticator(store)}.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the request not to be served")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", "rsk_valid")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if strings.Contains(rr.Body.String(), "locked") {
+		t.Errorf("expected the cause not to be returned, got %q", rr.Body.String())
+	}
+}
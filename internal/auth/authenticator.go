@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Returned by an authenticator when the request does not carry
+// credentials it knows how to check, so the next one can try
+var ErrNoCredentials = errors.New("no credentials for this authenticator")
+
+// Identity of an authenticated caller
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Method  string // Authenticator that accepted the request
+}
+
+// Report whether the principal was granted a given scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Anything able to turn a request into a principal
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Ordered list of authenticators tried one after the other
+type Chain []Authenticator
+
+// Try every authenticator until one accepts the request.
+// Authenticators answering ErrNoCredentials are skipped,
+// any other error stops the chain.
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	err := fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoTokenSent)
+	for _, a := range c {
+		principal, authErr := a.Authenticate(r)
+		if authErr == nil {
+			return principal, nil
+		}
+		if !errors.Is(authErr, ErrNoCredentials) {
+			return nil, authErr
+		}
+		// Keep the most specific reason, a token nobody recognised
+		// is worth more than a missing header
+		if !isMissingCredentials(authErr) || isMissingCredentials(err) {
+			err = authErr
+		}
+	}
+	return nil, err
+}
+
+// Report whether the error means no usable credentials were sent
+func isMissingCredentials(err error) bool {
+	return errors.Is(err, models.ErrNoTokenSent) ||
+		errors.Is(err, models.ErrNotBearer) ||
+		errors.Is(err, models.ErrNoClientCert)
+}
+
+// Extract the token of a "Bearer" authorization header
+func bearerToken(r *http.Request) (string, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoTokenSent)
+	}
+
+	const prefix = "Bearer "
+	if !strings.HasPrefix(authHeader, prefix) {
+		return "", fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNotBearer)
+	}
+
+	token := strings.TrimPrefix(authHeader, prefix)
+	if token == "" {
+		return "", fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoTokenSent)
+	}
+	return token, nil
+}
+
+type principalKey struct{}
+
+// Attach a principal to a request context
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	if recorder, ok := ctx.Value(recorderKey{}).(*principalRecorder); ok {
+		recorder.principal = principal
+	}
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Retrieve the principal set by the middleware, nil when anonymous
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+type recorderKey struct{}
+
+// Holder of the principal authenticated further down the handler chain
+type principalRecorder struct {
+	principal *Principal
+}
+
+// Prepare a context recording the principal set by the middleware,
+// for outer middlewares (e.g. access logs) which only see their own request
+func RecordPrincipal(ctx context.Context) (context.Context, func() *Principal) {
+	recorder := &principalRecorder{}
+	return context.WithValue(ctx, recorderKey{}, recorder), func() *Principal { return recorder.principal }
+}
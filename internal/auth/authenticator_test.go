@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flmailla/resume/models"
+)
+
+type mockAuthenticator struct {
+	authenticateFunc func(r *http.Request) (*Principal, error)
+}
+
+func (m *mockAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	return m.authenticateFunc(r)
+}
+
+func skipping(err error) Authenticator {
+	return &mockAuthenticator{authenticateFunc: func(r *http.Request) (*Principal, error) {
+		return nil, fmt.Errorf("%w: %w", ErrNoCredentials, err)
+	}}
+}
+
+func accepting(subject string) Authenticator {
+	return &mockAuthenticator{authenticateFunc: func(r *http.Request) (*Principal, error) {
+		return &Principal{Subject: subject}, nil
+	}}
+}
+
+func failing(err error) Authenticator {
+	return &mockAuthenticator{authenticateFunc: func(r *http.Request) (*Principal, error) {
+		return nil, err
+	}}
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	tests := []struct {
+		name        string
+		chain       Chain
+		wantSubject string
+		wantMissing bool
+		wantErr     bool
+	}{
+		{
+			name:        "empty chain",
+			chain:       Chain{},
+			wantMissing: true,
+			wantErr:     true,
+		},
+		{
+			name:        "first authenticator skips, second accepts",
+			chain:       Chain{skipping(errors.New("not mine")), accepting("batch")},
+			wantSubject: "batch",
+		},
+		{
+			name:    "failure stops the chain",
+			chain:   Chain{failing(errors.New("bad signature")), accepting("batch")},
+			wantErr: true,
+		},
+		{
+			name:        "every authenticator misses the token",
+			chain:       Chain{skipping(models.ErrNoTokenSent), skipping(models.ErrNotBearer)},
+			wantMissing: true,
+			wantErr:     true,
+		},
+		{
+			name:        "unknown token is preferred over missing token",
+			chain:       Chain{skipping(errors.New("not mine")), skipping(models.ErrNoTokenSent)},
+			wantMissing: false,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			principal, err := tt.chain.Authenticate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if isMissingCredentials(err) != tt.wantMissing {
+					t.Errorf("expected missing token %v, got error %v", tt.wantMissing, err)
+				}
+				return
+			}
+
+			if principal.Subject != tt.wantSubject {
+				t.Errorf("expected subject %q, got %q", tt.wantSubject, principal.Subject)
+			}
+		})
+	}
+}
+
+func TestChainAuthMiddlewareSetsPrincipal(t *testing.T) {
+	var got *Principal
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = PrincipalFromContext(r.Context())
+		w.Write([]byte("OK"))
+	})
+	middleware := Chain{accepting("batch")}.AuthMiddleware(nextHandler)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	ctx, recorded := RecordPrincipal(req.Context())
+	rr := httptest.NewRecorder()
+	middleware.ServeHTTP(rr, req.WithContext(ctx))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	if got == nil || got.Subject != "batch" {
+		t.Errorf("expected principal batch in context, got %+v", got)
+	}
+
+	if recorded() != got {
+		t.Errorf("expected the principal to be recorded for outer middlewares, got %+v", recorded())
+	}
+}
+
+func TestPrincipalHasScope(t *testing.T) {
+	principal := &Principal{Scopes: []string{"read", "write"}}
+
+	if !principal.HasScope("write") {
+		t.Error("expected write scope to be granted")
+	}
+
+	if principal.HasScope("admin") {
+		t.Error("expected admin scope not to be granted")
+	}
+}
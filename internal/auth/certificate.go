@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Client certificate identity mapped to a principal
+type CertIdentity struct {
+	Kind      string // cn, dns, uri or email
+	Value     string
+	Principal string
+	Scopes    []string
+}
+
+// Authenticator accepting verified TLS client certificates
+type CertAuthenticator struct {
+	identities []CertIdentity
+}
+
+// Instantiate a new client certificate authenticator
+func NewCertAuthenticator(identities []CertIdentity) *CertAuthenticator {
+	return &CertAuthenticator{identities: identities}
+}
+
+// Parse identities written as "<kind>:<value>=<principal> [scope...]"
+// and separated by semicolons, e.g.
+// "dns:batch.internal=batch read;uri:spiffe://corp/sync=sync read write"
+func ParseCertIdentities(spec string) ([]CertIdentity, error) {
+	var identities []CertIdentity
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		sep := strings.LastIndex(entry, "=")
+		if sep < 0 {
+			return nil, fmt.Errorf("invalid certificate identity %q: missing principal", entry)
+		}
+
+		kind, value, found := strings.Cut(entry[:sep], ":")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid certificate identity %q: expected <kind>:<value>", entry)
+		}
+		switch kind {
+		case "cn", "dns", "uri", "email":
+		default:
+			return nil, fmt.Errorf("invalid certificate identity %q: unknown kind %s", entry, kind)
+		}
+
+		fields := strings.Fields(entry[sep+1:])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid certificate identity %q: missing principal", entry)
+		}
+
+		identities = append(identities, CertIdentity{
+			Kind:      kind,
+			Value:     value,
+			Principal: fields[0],
+			Scopes:    fields[1:],
+		})
+	}
+	return identities, nil
+}
+
+// Server TLS configuration, asking for a client certificate
+// signed by the CA bundle when one is given.
+// Clients without certificate can still use bearer tokens.
+func NewServerTLSConfig(clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in client CA bundle %s", clientCAFile)
+	}
+
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+// Map the verified client certificate to a principal
+func (c *CertAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoClientCert)
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	for _, identity := range c.identities {
+		if identity.matches(leaf) {
+			return &Principal{
+				Subject: identity.Principal,
+				Scopes:  identity.Scopes,
+				Method:  "mtls",
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: certificate %s is not mapped to a principal", ErrNoCredentials, leaf.Subject)
+}
+
+// Report whether the certificate carries the identity
+func (i CertIdentity) matches(cert *x509.Certificate) bool {
+	switch i.Kind {
+	case "cn":
+		return cert.Subject.CommonName == i.Value
+	case "dns":
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, i.Value) {
+				return true
+			}
+		}
+	case "uri":
+		for _, uri := range cert.URIs {
+			if uri.String() == i.Value {
+				return true
+			}
+		}
+	case "email":
+		for _, email := range cert.EmailAddresses {
+			if strings.EqualFold(email, i.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Issue a certificate signed by parent, self-signed when parent is nil
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestParseCertIdentities(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []CertIdentity
+		wantErr bool
+	}{
+		{
+			name: "several identities",
+			spec: "dns:batch.internal=batch read; uri:spiffe://corp/sync=sync read write",
+			want: []CertIdentity{
+				{Kind: "dns", Value: "batch.internal", Principal: "batch", Scopes: []string{"read"}},
+				{Kind: "uri", Value: "spiffe://corp/sync", Principal: "sync", Scopes: []string{"read", "write"}},
+			},
+		},
+		{
+			name: "identity without scope",
+			spec: "cn:integration=integration",
+			want: []CertIdentity{
+				{Kind: "cn", Value: "integration", Principal: "integration", Scopes: []string{}},
+			},
+		},
+		{
+			name:    "unknown kind",
+			spec:    "ip:10.0.0.1=batch",
+			wantErr: true,
+		},
+		{
+			name:    "missing principal",
+			spec:    "dns:batch.internal",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCertIdentities(tt.spec)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected identities %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCertAuthenticate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://corp/sync")
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	client := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "sync-client"},
+		URIs:        []*url.URL{spiffe},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	authenticator := NewCertAuthenticator([]CertIdentity{
+		{Kind: "uri", Value: "spiffe://corp/sync", Principal: "sync", Scopes: []string{"read"}},
+	})
+
+	tests := []struct {
+		name        string
+		state       *tls.ConnectionState
+		wantSubject string
+		wantMissing bool
+		wantErr     bool
+	}{
+		{
+			name:        "plain HTTP",
+			state:       nil,
+			wantMissing: true,
+			wantErr:     true,
+		},
+		{
+			name:        "TLS without client certificate",
+			state:       &tls.ConnectionState{},
+			wantMissing: true,
+			wantErr:     true,
+		},
+		{
+			name:        "mapped certificate",
+			state:       &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client.Leaf, ca.Leaf}}},
+			wantSubject: "sync",
+		},
+		{
+			name:    "certificate not mapped",
+			state:   &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{ca.Leaf}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.TLS = tt.state
+
+			principal, err := authenticator.Authenticate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if isMissingCredentials(err) != tt.wantMissing {
+					t.Errorf("expected missing credentials %v, got %v", tt.wantMissing, err)
+				}
+				return
+			}
+
+			if principal.Subject != tt.wantSubject || principal.Method != "mtls" {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestMutualTLSServer(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	server := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "batch"},
+		DNSNames:    []string{"batch.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Leaf.Raw}), 0o600)
+
+	tlsConfig, err := NewServerTLSConfig(caFile)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{server}
+
+	chain := Chain{NewCertAuthenticator([]CertIdentity{{Kind: "dns", Value: "batch.internal", Principal: "batch"}})}
+	ts := httptest.NewUnstartedServer(chain.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(PrincipalFromContext(r.Context()).Subject))
+	})))
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	tests := []struct {
+		name           string
+		certificates   []tls.Certificate
+		expectedStatus int
+	}{
+		{
+			name:           "with client certificate",
+			certificates:   []tls.Certificate{client},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "without client certificate",
+			certificates:   nil,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: tt.certificates,
+			}}}
+
+			resp, err := httpClient.Get(ts.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	if _, err := NewServerTLSConfig(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected error for missing CA bundle, got none")
+	}
+}
@@ -1,7 +1,29 @@
 package auth
 
-const aud string = "874b61e3-ef5a-454b-828e-1275a4eb14b6"
-const iss string = "https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/v2.0"
+import "time"
+
+// Entra ID application trusted by default
+const DefaultAudience string = "874b61e3-ef5a-454b-828e-1275a4eb14b6"
+const DefaultIssuer string = "https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/v2.0"
+const DefaultJWKSURL string = "https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/discovery/v2.0/keys"
+
+const localAud string = "resume"
+const hmacIss string = "resume-local"
+
+const apiKeyPrefix string = "rsk_"
+
+// Shortest time between two writes of the last usage of an API key,
+// the single SQLite writer not being taken by every request
+const apiKeyTouchInterval = 1 * time.Minute
+
+// Paths reachable without any credentials
+var unauthenticatedPaths = map[string]bool{
+	"/health":                true,
+	"/health/live":           true,
+	"/health/ready":          true,
+	"/oauth/token":           true,
+	"/.well-known/jwks.json": true,
+}
 
 const ascii401 string = `
     d8888   .d8888b.   d888
@@ -22,3 +44,13 @@ d88   888  888    888      "Y8b.
 8888888888 888    888 888    888
       888  Y88b  d88P Y88b  d88P
       888   "Y8888P"   "Y8888P"`
+
+const ascii429 string = `
+    d8888   .d8888b.   .d8888b.
+   d8P888  d88P  Y88b d88P  Y88b
+  d8P 888         888 888    888
+ d8P  888       .d88P Y88b. d888
+d88   888   .od888P"   "Y888P888
+8888888888 d88P"             888
+      888  888"       Y88b  d88P
+      888  888888888   "Y8888P"`
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHMACAuthenticate(t *testing.T) {
+	authenticator := NewHMACAuthenticator([]byte("local-secret"))
+
+	valid, err := authenticator.Sign("dev", []string{"read", "write"}, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	expired, err := authenticator.Sign("dev", nil, -time.Hour)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	forged, err := NewHMACAuthenticator([]byte("other-secret")).Sign("dev", nil, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantScopes []string
+		wantSkip   bool
+		wantErr    bool
+	}{
+		{
+			name:       "valid token",
+			token:      valid,
+			wantScopes: []string{"read", "write"},
+		},
+		{
+			name:    "expired token",
+			token:   expired,
+			wantErr: true,
+		},
+		{
+			name:    "token signed with another secret",
+			token:   forged,
+			wantErr: true,
+		},
+		{
+			name:     "not a JWT",
+			token:    "rsk_key",
+			wantSkip: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			principal, err := authenticator.Authenticate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, ErrNoCredentials) != tt.wantSkip {
+				t.Errorf("expected skip %v, got error %v", tt.wantSkip, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(principal.Scopes, tt.wantScopes) {
+				t.Errorf("expected scopes %v, got %v", tt.wantScopes, principal.Scopes)
+			}
+		})
+	}
+}
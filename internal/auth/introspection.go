@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Token introspection response (RFC 7662 section 2.2)
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Sub      string `json:"sub,omitempty"`
+	Iss      string `json:"iss,omitempty"`
+	Exp      int64  `json:"exp,omitempty"`
+	Iat      int64  `json:"iat,omitempty"`
+}
+
+// Claims of an active token, kept until the token expires, or for the
+// cache TTL of the validator when the response has no expiration.
+// Rejected tokens are kept with their error for the negative TTL.
+type introspectionEntry struct {
+	claims  *Claims
+	err     error
+	expires time.Time
+}
+
+// Validator of opaque tokens relying on an introspection endpoint
+type IntrospectionValidator struct {
+	endpoint     string
+	clientId     string
+	clientSecret string
+	issuer       string
+	httpClient   *http.Client
+	cacheTTL     time.Duration
+	negativeTTL  time.Duration
+	mu           sync.Mutex
+	cache        map[string]introspectionEntry
+}
+
+// Instantiate a new introspection validator.
+// The issuer selects the JWT shaped tokens to introspect,
+// opaque tokens are always introspected.
+func NewIntrospectionValidator(endpoint string, clientId string, clientSecret string, issuer string) *IntrospectionValidator {
+	return &IntrospectionValidator{
+		endpoint:     endpoint,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		issuer:       issuer,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cacheTTL:    1 * time.Minute,
+		negativeTTL: 10 * time.Second,
+		cache:       make(map[string]introspectionEntry),
+	}
+}
+
+// Ask the introspection endpoint about a token
+func (v *IntrospectionValidator) introspect(ctx context.Context, token string) (*introspectionResponse, error) {
+	ctx, span := tracer.Start(ctx, "auth.introspect", trace.WithAttributes(attribute.String("url.full", v.endpoint)))
+	defer span.End()
+
+	introspection, err := v.requestIntrospection(ctx, token)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Bool("token.active", introspection.Active))
+	return introspection, nil
+}
+
+func (v *IntrospectionValidator) requestIntrospection(ctx context.Context, token string) (*introspectionResponse, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build introspection request: %w", err)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(v.clientId), url.QueryEscape(v.clientSecret))
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status: %d", resp.StatusCode)
+	}
+
+	var introspection introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&introspection); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	return &introspection, nil
+}
+
+// Map an introspection response into the JWT claims model
+func (r *introspectionResponse) claims() *Claims {
+	subject := r.Sub
+	if subject == "" {
+		subject = r.ClientID
+	}
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: subject,
+			Issuer:  r.Iss,
+		},
+		Scope: r.Scope,
+	}
+	if r.Exp != 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(r.Exp, 0))
+	}
+	if r.Iat != 0 {
+		claims.IssuedAt = jwt.NewNumericDate(time.Unix(r.Iat, 0))
+	}
+	return claims
+}
+
+// Return the claims of a token, from the cache when possible
+func (v *IntrospectionValidator) getClaims(ctx context.Context, token string) (*Claims, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	v.mu.Lock()
+	entry, exists := v.cache[key]
+	v.mu.Unlock()
+	if exists && now.Before(entry.expires) {
+		return entry.claims, entry.err
+	}
+
+	introspection, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replayed revoked or unknown tokens do not reach the endpoint each time
+	if !introspection.Active {
+		err := fmt.Errorf("%w: token is not active", ErrNoCredentials)
+		v.store(key, introspectionEntry{err: err, expires: now.Add(v.negativeTTL)}, now)
+		return nil, err
+	}
+
+	if v.issuer != "" && introspection.Iss != "" && introspection.Iss != v.issuer {
+		err := fmt.Errorf("%w: unexpected issuer %s", ErrNoCredentials, introspection.Iss)
+		v.store(key, introspectionEntry{err: err, expires: now.Add(v.negativeTTL)}, now)
+		return nil, err
+	}
+
+	claims := introspection.claims()
+	expires := now.Add(v.cacheTTL)
+	if claims.ExpiresAt != nil {
+		if !now.Before(claims.ExpiresAt.Time) {
+			return nil, fmt.Errorf("token validation failed: %w", jwt.ErrTokenExpired)
+		}
+		expires = claims.ExpiresAt.Time
+	}
+	v.store(key, introspectionEntry{claims: claims, expires: expires}, now)
+
+	return claims, nil
+}
+
+// Cache an entry and drop the expired ones
+func (v *IntrospectionValidator) store(key string, entry introspectionEntry, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for k, e := range v.cache {
+		if !now.Before(e.expires) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = entry
+}
+
+// Authenticate opaque bearer tokens, and JWT shaped ones of the validator issuer
+func (v *IntrospectionValidator) Authenticate(r *http.Request) (*Principal, error) {
+	tokenString, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if token, err := peekToken(tokenString); err == nil {
+		if issuer, _ := token.Claims.GetIssuer(); v.issuer == "" || issuer != v.issuer {
+			return nil, fmt.Errorf("%w: unexpected issuer %s", ErrNoCredentials, issuer)
+		}
+	}
+
+	claims, err := v.getClaims(r.Context(), tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Scopes:  claims.Scopes(),
+		Method:  "introspection",
+	}, nil
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestIntrospectionAuthenticate(t *testing.T) {
+	responses := map[string]introspectionResponse{
+		"opaque-active": {
+			Active:   true,
+			Scope:    "read write",
+			ClientID: "gateway-client",
+			Iss:      "https://gateway",
+			Exp:      time.Now().Add(time.Hour).Unix(),
+		},
+		"opaque-no-exp": {
+			Active: true,
+			Sub:    "user",
+		},
+		"opaque-expired": {
+			Active: true,
+			Sub:    "user",
+			Exp:    time.Now().Add(-time.Hour).Unix(),
+		},
+		"opaque-other-issuer": {
+			Active: true,
+			Sub:    "user",
+			Iss:    "https://elsewhere",
+		},
+	}
+	calls := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "resume" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		token := r.PostForm.Get("token")
+		calls[token]++
+		json.NewEncoder(w).Encode(responses[token])
+	}))
+	defer server.Close()
+
+	validator := NewIntrospectionValidator(server.URL, "resume", "s3cret", "https://gateway")
+
+	tests := []struct {
+		name        string
+		token       string
+		wantSubject string
+		wantScopes  []string
+		wantSkip    bool
+		wantErr     bool
+	}{
+		{
+			name:        "active token",
+			token:       "opaque-active",
+			wantSubject: "gateway-client",
+			wantScopes:  []string{"read", "write"},
+		},
+		{
+			name:        "active token without expiration",
+			token:       "opaque-no-exp",
+			wantSubject: "user",
+			wantScopes:  []string{},
+		},
+		{
+			name:     "inactive token",
+			token:    "opaque-unknown",
+			wantSkip: true,
+			wantErr:  true,
+		},
+		{
+			name:    "expired token",
+			token:   "opaque-expired",
+			wantErr: true,
+		},
+		{
+			name:     "token of another issuer",
+			token:    "opaque-other-issuer",
+			wantSkip: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			principal, err := validator.Authenticate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, ErrNoCredentials) != tt.wantSkip {
+				t.Errorf("expected skip %v, got error %v", tt.wantSkip, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if principal.Subject != tt.wantSubject || principal.Method != "introspection" {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+
+			if !reflect.DeepEqual(principal.Scopes, tt.wantScopes) {
+				t.Errorf("expected scopes %v, got %v", tt.wantScopes, principal.Scopes)
+			}
+		})
+	}
+
+	t.Run("active results are cached until exp or for the cache ttl", func(t *testing.T) {
+		for range 3 {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer opaque-active")
+			validator.Authenticate(req)
+
+			req = httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer opaque-no-exp")
+			validator.Authenticate(req)
+		}
+
+		if calls["opaque-active"] != 1 {
+			t.Errorf("expected a single introspection call, got %d", calls["opaque-active"])
+		}
+
+		if calls["opaque-no-exp"] != 1 {
+			t.Errorf("expected a single introspection call without exp, got %d", calls["opaque-no-exp"])
+		}
+
+		// Past the cache TTL, tokens without exp are introspected again
+		validator.mu.Lock()
+		for key, entry := range validator.cache {
+			if entry.claims != nil && entry.claims.ExpiresAt == nil {
+				entry.expires = time.Now()
+				validator.cache[key] = entry
+			}
+		}
+		validator.mu.Unlock()
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer opaque-no-exp")
+		validator.Authenticate(req)
+		if calls["opaque-no-exp"] != 2 {
+			t.Errorf("expected tokens without exp to be introspected again, got %d calls", calls["opaque-no-exp"])
+		}
+	})
+
+	t.Run("inactive results are cached for the negative ttl", func(t *testing.T) {
+		send := func() error {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer opaque-unknown")
+			_, err := validator.Authenticate(req)
+			return err
+		}
+		for range 3 {
+			if err := send(); !errors.Is(err, ErrNoCredentials) {
+				t.Fatalf("expected the cached token to be skipped, got %v", err)
+			}
+		}
+		if calls["opaque-unknown"] != 1 {
+			t.Errorf("expected a single introspection call, got %d", calls["opaque-unknown"])
+		}
+
+		validator.mu.Lock()
+		for key, entry := range validator.cache {
+			if entry.err != nil {
+				entry.expires = time.Now()
+				validator.cache[key] = entry
+			}
+		}
+		validator.mu.Unlock()
+
+		send()
+		if calls["opaque-unknown"] != 2 {
+			t.Errorf("expected inactive tokens to be introspected again, got %d calls", calls["opaque-unknown"])
+		}
+	})
+}
+
+func TestIntrospectionPropagatesRequestContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(introspectionResponse{Active: true, Sub: "user"})
+	}))
+	defer server.Close()
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	})
+	validator := NewIntrospectionValidator(server.URL, "resume", "s3cret", "")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer opaque")
+	req = req.WithContext(trace.ContextWithRemoteSpanContext(req.Context(), parent))
+	if _, err := validator.Authenticate(req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(traceparent, parent.TraceID().String()) {
+		t.Errorf("expected traceparent of trace %s, got %q", parent.TraceID(), traceparent)
+	}
+
+	// A caller gone away cancels the introspection
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer other-opaque")
+	if _, err := validator.Authenticate(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the introspection to be cancelled, got %v", err)
+	}
+}
+
+func TestIntrospectionSelectsJWTByIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(introspectionResponse{Active: true, Sub: "user"})
+	}))
+	defer server.Close()
+
+	validator := NewIntrospectionValidator(server.URL, "resume", "s3cret", "https://gateway")
+	secret := []byte("secret")
+
+	tests := []struct {
+		name     string
+		issuer   string
+		wantSkip bool
+	}{
+		{
+			name:     "JWT of the configured issuer",
+			issuer:   "https://gateway",
+			wantSkip: false,
+		},
+		{
+			name:     "JWT of another issuer",
+			issuer:   DefaultIssuer,
+			wantSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+				RegisteredClaims: jwt.RegisteredClaims{Issuer: tt.issuer},
+			}).SignedString(secret)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			_, err := validator.Authenticate(req)
+
+			if errors.Is(err, ErrNoCredentials) != tt.wantSkip {
+				t.Errorf("expected skip %v, got error %v", tt.wantSkip, err)
+			}
+		})
+	}
+}
+
+func TestIntrospectionEndpointFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	validator := NewIntrospectionValidator(server.URL, "resume", "s3cret", "")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer opaque")
+
+	_, err := validator.Authenticate(req)
+	if err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected a hard failure, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flmailla/resume/logger"
+	"github.com/flmailla/resume/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Storage of the registered OAuth2 clients
+type ClientStore interface {
+	GetOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error)
+}
+
+// Embedded OAuth2 authorization server
+// supporting the client credentials grant only
+type TokenIssuer struct {
+	key      *rsa.PrivateKey
+	kid      string
+	issuer   string
+	audience string
+	tokenTTL time.Duration
+	clients  ClientStore
+}
+
+// OAuth2 access token response (RFC 6749 section 5.1)
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// OAuth2 error response (RFC 6749 section 5.2)
+type tokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Instantiate a new token issuer signing with the given key
+func NewTokenIssuer(key *rsa.PrivateKey, issuer string, clients ClientStore) *TokenIssuer {
+	return &TokenIssuer{
+		key:      key,
+		kid:      keyThumbprint(&key.PublicKey),
+		issuer:   issuer,
+		audience: localAud,
+		tokenTTL: 1 * time.Hour,
+		clients:  clients,
+	}
+}
+
+// Read a PEM encoded RSA private key,
+// or generate an ephemeral one when no path is given
+func LoadSigningKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode signing key: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type: %T", parsed)
+	}
+	return key, nil
+}
+
+// Create a new client id, secret and the secret hash to store
+func GenerateClientCredentials() (string, string, string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate client id: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	clientSecret := base64.RawURLEncoding.EncodeToString(secret)
+	return hex.EncodeToString(id), clientSecret, HashSecret(clientSecret), nil
+}
+
+// Validator trusting the tokens minted by this issuer
+func (i *TokenIssuer) Validator() *JWTValidator {
+	return NewStaticJWTValidator(i.issuer, i.audience, map[string]*rsa.PublicKey{
+		i.kid: &i.key.PublicKey,
+	})
+}
+
+// Public keys of the issuer as a JSON Web Key Set
+func (i *TokenIssuer) JWKS() JWKS {
+	return JWKS{
+		Keys: []JWK{
+			{
+				Kty: "RSA",
+				Kid: i.kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(i.key.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.PublicKey.E)).Bytes()),
+				Alg: jwt.SigningMethodRS256.Alg(),
+			},
+		},
+	}
+}
+
+// Sign an access token for a client
+func (i *TokenIssuer) Mint(clientId string, scopes []string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientId,
+			Audience:  jwt.ClaimStrings{i.audience},
+			Issuer:    i.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.tokenTTL)),
+		},
+		Scope: strings.Join(scopes, " "),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.kid
+	return token.SignedString(i.key)
+}
+
+// Serve the issuer JSON Web Key Set
+func (i *TokenIssuer) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(i.JWKS())
+}
+
+// Token endpoint of the client credentials grant (RFC 6749 section 4.4)
+func (i *TokenIssuer) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", "malformed form body")
+		return
+	}
+
+	if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+
+	clientId, clientSecret, basic := r.BasicAuth()
+	if !basic {
+		clientId = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	client, err := i.authenticateClient(r.Context(), clientId, clientSecret)
+	if err != nil && !errors.Is(err, models.ErrClientNotFound) && !errors.Is(err, models.ErrUnauthorized) {
+		logger.FromContext(r.Context()).Error("Failed to authenticate the client", "client_id", clientId, "error", err)
+		writeTokenError(w, http.StatusInternalServerError, "server_error", "failed to authenticate the client")
+		return
+	}
+	if err != nil {
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="resume"`)
+		}
+		writeTokenError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	scopes, err := grantedScopes(client.Scopes, strings.Fields(r.PostForm.Get("scope")))
+	if err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+
+	accessToken, err := i.Mint(client.ClientID, scopes)
+	if err != nil {
+		writeTokenError(w, http.StatusInternalServerError, "server_error", "failed to sign the token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(i.tokenTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// Check the client secret against the store
+func (i *TokenIssuer) authenticateClient(ctx context.Context, clientId string, clientSecret string) (*models.OAuthClient, error) {
+	if clientId == "" || clientSecret == "" {
+		return nil, models.ErrUnauthorized
+	}
+
+	client, err := i.clients.GetOAuthClientByClientId(ctx, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.RevokedAt != nil {
+		return nil, models.ErrUnauthorized
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, models.ErrUnauthorized
+	}
+	return client, nil
+}
+
+// Restrict the requested scopes to the ones registered for the client.
+// No requested scope means every registered scope.
+func grantedScopes(registered []string, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return registered, nil
+	}
+
+	allowed := make(map[string]bool, len(registered))
+	for _, scope := range registered {
+		allowed[scope] = true
+	}
+
+	for _, scope := range requested {
+		if !allowed[scope] {
+			return nil, errors.New("scope " + scope + " is not granted to the client")
+		}
+	}
+	return requested, nil
+}
+
+// RFC 7638 thumbprint of a RSA public key, used as key ID
+func keyThumbprint(key *rsa.PublicKey) string {
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code string, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(tokenError{Error: code, ErrorDescription: description})
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flmailla/resume/models"
+)
+
+type mockClientStore struct {
+	clients map[string]*models.OAuthClient
+	err     error
+}
+
+func (m *mockClientStore) GetOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if client, exists := m.clients[clientId]; exists {
+		return client, nil
+	}
+	return nil, models.ErrClientNotFound
+}
+
+func newTestIssuer(t *testing.T) *TokenIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	store := &mockClientStore{clients: map[string]*models.OAuthClient{
+		"frontend": {ClientID: "frontend", SecretHash: HashSecret("s3cret"), Scopes: []string{"read", "write"}},
+	}}
+	return NewTokenIssuer(key, "http://localhost:8090", store)
+}
+
+func TestTokenHandler(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	tests := []struct {
+		name           string
+		form           url.Values
+		basicUser      string
+		basicPassword  string
+		wantStatusCode int
+		wantError      string
+		wantScope      string
+	}{
+		{
+			name:           "client credentials in the body",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"frontend"}, "client_secret": {"s3cret"}},
+			wantStatusCode: http.StatusOK,
+			wantScope:      "read write",
+		},
+		{
+			name:           "client credentials with basic auth and reduced scope",
+			form:           url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}},
+			basicUser:      "frontend",
+			basicPassword:  "s3cret",
+			wantStatusCode: http.StatusOK,
+			wantScope:      "read",
+		},
+		{
+			name:           "unsupported grant",
+			form:           url.Values{"grant_type": {"password"}},
+			wantStatusCode: http.StatusBadRequest,
+			wantError:      "unsupported_grant_type",
+		},
+		{
+			name:           "wrong secret",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"frontend"}, "client_secret": {"nope"}},
+			wantStatusCode: http.StatusUnauthorized,
+			wantError:      "invalid_client",
+		},
+		{
+			name:           "unknown client",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"other"}, "client_secret": {"s3cret"}},
+			wantStatusCode: http.StatusUnauthorized,
+			wantError:      "invalid_client",
+		},
+		{
+			name:           "scope not registered",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"frontend"}, "client_secret": {"s3cret"}, "scope": {"admin"}},
+			wantStatusCode: http.StatusBadRequest,
+			wantError:      "invalid_scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/oauth/token", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.basicUser != "" {
+				req.SetBasicAuth(tt.basicUser, tt.basicPassword)
+			}
+			rr := httptest.NewRecorder()
+
+			issuer.TokenHandler(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Fatalf("expected status %d, got %d", tt.wantStatusCode, rr.Code)
+			}
+
+			if tt.wantError != "" {
+				var got tokenError
+				if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+					t.Fatalf("failed to unmarshal response body: %v", err)
+				}
+				if got.Error != tt.wantError {
+					t.Errorf("expected error %q, got %q", tt.wantError, got.Error)
+				}
+				return
+			}
+
+			var got tokenResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response body: %v", err)
+			}
+			if got.TokenType != "Bearer" || got.Scope != tt.wantScope {
+				t.Errorf("unexpected token response %+v", got)
+			}
+
+			// The minted token must be accepted by the issuer validator
+			authReq := httptest.NewRequest("GET", "/", nil)
+			authReq.Header.Set("Authorization", "Bearer "+got.AccessToken)
+			principal, err := issuer.Validator().Authenticate(authReq)
+			if err != nil {
+				t.Fatalf("expected minted token to be valid, got %v", err)
+			}
+			if principal.Subject != "frontend" || strings.Join(principal.Scopes, " ") != tt.wantScope {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestTokenHandlerStoreFailure(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.clients = &mockClientStore{err: errors.New("database is locked")}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {"frontend"}, "client_secret": {"s3cret"}}
+	req := httptest.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	issuer.TokenHandler(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	var got tokenError
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response body: %v", err)
+	}
+	if got.Error != "server_error" || strings.Contains(rr.Body.String(), "locked") {
+		t.Errorf("expected a server_error without the cause, got %s", rr.Body.String())
+	}
+}
+
+func TestIssuerTokensSkippedByOtherValidators(t *testing.T) {
+	issuer := newTestIssuer(t)
+	token, err := issuer.Mint("frontend", nil)
+	if err != nil {
+		t.Fatalf("failed to mint token: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	_, err = NewJWTValidator("http://nonexistent").Authenticate(req)
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected token of another issuer to be skipped, got %v", err)
+	}
+
+	principal, err := Chain{NewJWTValidator("http://nonexistent"), issuer.Validator()}.Authenticate(req)
+	if err != nil {
+		t.Fatalf("expected chain to accept the token, got %v", err)
+	}
+	if principal.Subject != "frontend" {
+		t.Errorf("expected subject frontend, got %s", principal.Subject)
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	rr := httptest.NewRecorder()
+	issuer.JWKSHandler(rr, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var got JWKS
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response body: %v", err)
+	}
+
+	if len(got.Keys) != 1 || got.Keys[0].Kid != issuer.kid {
+		t.Fatalf("unexpected JWKS %+v", got)
+	}
+
+	key, err := NewJWTValidator("").jwkToRSAPublicKey(got.Keys[0])
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(key, &issuer.key.PublicKey) {
+		t.Error("expected published key to match the signing key")
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	pkcs1Path := filepath.Join(dir, "pkcs1.pem")
+	pkcs8Path := filepath.Join(dir, "pkcs8.pem")
+	os.WriteFile(pkcs1Path, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600)
+	os.WriteFile(pkcs8Path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0o600)
+
+	for _, path := range []string{pkcs1Path, pkcs8Path} {
+		loaded, err := LoadSigningKey(path)
+		if err != nil {
+			t.Fatalf("expected no error for %s, got %v", path, err)
+		}
+		if !loaded.Equal(key) {
+			t.Errorf("expected loaded key to match for %s", path)
+		}
+	}
+
+	if _, err := LoadSigningKey(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("expected error for missing file, got none")
+	}
+
+	generated, err := LoadSigningKey("")
+	if err != nil || generated == nil {
+		t.Errorf("expected an ephemeral key, got %v", err)
+	}
+}
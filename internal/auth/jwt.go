@@ -1,17 +1,28 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/flmailla/resume/models"
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/flmailla/resume/internal/auth")
+
 // List of Json Web Key Sets
 type JWKS struct {
 	Keys []JWK `json:"keys"`
@@ -30,21 +41,41 @@ type JWK struct {
 // Standard claims
 type Claims struct {
 	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"` // OAuth2 space separated scopes
+	Scp   string   `json:"scp,omitempty"`   // Entra ID delegated scopes
+	Roles []string `json:"roles,omitempty"` // Entra ID application roles
+}
+
+// Every scope granted by the token, whatever the claim carrying it
+func (c *Claims) Scopes() []string {
+	scopes := strings.Fields(c.Scope)
+	scopes = append(scopes, strings.Fields(c.Scp)...)
+	return append(scopes, c.Roles...)
 }
 
 // JWT validator components
 type JWTValidator struct {
 	jwksURL    string
+	issuer     string
+	audience   string
 	httpClient *http.Client
+	mu         sync.RWMutex
 	keyCache   map[string]*rsa.PublicKey
 	cacheTime  time.Time
 	cacheTTL   time.Duration
 }
 
-// Instantiate a new JWT Validator
+// Instantiate a new JWT Validator for the default Entra ID application
 func NewJWTValidator(jwksURL string) *JWTValidator {
+	return NewIssuerJWTValidator(jwksURL, DefaultIssuer, DefaultAudience)
+}
+
+// Instantiate a new JWT Validator for a given issuer and audience
+func NewIssuerJWTValidator(jwksURL string, issuer string, audience string) *JWTValidator {
 	return &JWTValidator{
-		jwksURL: jwksURL,
+		jwksURL:  jwksURL,
+		issuer:   issuer,
+		audience: audience,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -53,9 +84,39 @@ func NewJWTValidator(jwksURL string) *JWTValidator {
 	}
 }
 
+// Instantiate a JWT Validator trusting a fixed set of keys
+// No JWKS is fetched
+func NewStaticJWTValidator(issuer string, audience string, keys map[string]*rsa.PublicKey) *JWTValidator {
+	return &JWTValidator{
+		issuer:   issuer,
+		audience: audience,
+		keyCache: keys,
+	}
+}
+
 // Get The key sets from an URL defined in config.go
-func (v *JWTValidator) fetchJWKS() (*JWKS, error) {
-	resp, err := v.httpClient.Get(v.jwksURL)
+func (v *JWTValidator) fetchJWKS(ctx context.Context) (*JWKS, error) {
+	ctx, span := tracer.Start(ctx, "auth.jwks.fetch", trace.WithAttributes(attribute.String("url.full", v.jwksURL)))
+	defer span.End()
+
+	jwks, err := v.requestJWKS(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("jwks.keys", len(jwks.Keys)))
+	return jwks, nil
+}
+
+func (v *JWTValidator) requestJWKS(ctx context.Context) (*JWKS, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := v.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
 	}
@@ -99,38 +160,87 @@ func (v *JWTValidator) jwkToRSAPublicKey(jwk JWK) (*rsa.PublicKey, error) {
 }
 
 // Extract the RSA public Key for a given kid in the JWKS
-func (v *JWTValidator) getPublicKey(kid string) (*rsa.PublicKey, error) {
-	if time.Since(v.cacheTime) < v.cacheTTL {
-		if key, exists := v.keyCache[kid]; exists {
-			return key, nil
-		}
+func (v *JWTValidator) getPublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, exists := v.cachedKey(kid); exists {
+		return key, nil
 	}
 
-	jwks, err := v.fetchJWKS()
-	if err != nil {
+	if v.jwksURL == "" {
+		return nil, fmt.Errorf("key with ID %s not found", kid)
+	}
+
+	if err := v.refreshKeys(ctx); err != nil {
 		return nil, err
 	}
 
-	v.keyCache = make(map[string]*rsa.PublicKey)
-	v.cacheTime = time.Now()
+	if key, exists := v.cachedKey(kid); exists {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("key with ID %s not found", kid)
+}
+
+// Return a key of the cache, unless the cache expired
+func (v *JWTValidator) cachedKey(kid string) (*rsa.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.jwksURL != "" && time.Since(v.cacheTime) >= v.cacheTTL {
+		return nil, false
+	}
+	key, exists := v.keyCache[kid]
+	return key, exists
+}
+
+// Replace the cached keys by the ones of the JWKS
+func (v *JWTValidator) refreshKeys(ctx context.Context) error {
+	jwks, err := v.fetchJWKS(ctx)
+	recordJWKSFetch(err)
+	if err != nil {
+		return err
+	}
 
+	keys := make(map[string]*rsa.PublicKey)
 	for _, jwk := range jwks.Keys {
 		publicKey, err := v.jwkToRSAPublicKey(jwk)
 		if err != nil {
 			continue
 		}
-		v.keyCache[jwk.Kid] = publicKey
+		keys[jwk.Kid] = publicKey
 	}
 
-	if key, exists := v.keyCache[kid]; exists {
-		return key, nil
+	v.mu.Lock()
+	v.keyCache = keys
+	v.cacheTime = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+// Check that keys are cached and fresh, fetching them when they are not
+func (v *JWTValidator) CheckKeys(ctx context.Context) error {
+	v.mu.RLock()
+	warm := len(v.keyCache) > 0 && (v.jwksURL == "" || time.Since(v.cacheTime) < v.cacheTTL)
+	v.mu.RUnlock()
+	if warm {
+		return nil
 	}
 
-	return nil, fmt.Errorf("key with ID %s not found", kid)
+	if v.jwksURL != "" {
+		if err := v.refreshKeys(ctx); err != nil {
+			return err
+		}
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if len(v.keyCache) == 0 {
+		return models.ErrJWKSNotWarm
+	}
+	return nil
 }
 
 // Validate the token signature
-func (v *JWTValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+func (v *JWTValidator) keyFunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
 	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 	}
@@ -140,7 +250,7 @@ func (v *JWTValidator) keyFunc(token *jwt.Token) (interface{}, error) {
 		return nil, fmt.Errorf("token header missing kid")
 	}
 
-	return v.getPublicKey(kid)
+	return v.getPublicKey(ctx, kid)
 }
 
 // Validate custom claims in the JWT token
@@ -150,25 +260,76 @@ func (v *JWTValidator) validateCustomClaims(claims *Claims) error {
 
 // Globally check the validity of a JWT token
 func (v *JWTValidator) verifyToken(tokenString string) error {
+	_, err := v.parseToken(context.Background(), tokenString)
+	return err
+}
+
+// Check the validity of a JWT token and return its claims
+func (v *JWTValidator) parseToken(ctx context.Context, tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString,
 		claims,
-		v.keyFunc,
-		jwt.WithAudience(aud),
-		jwt.WithIssuer(iss),
+		func(token *jwt.Token) (interface{}, error) { return v.keyFunc(ctx, token) },
+		jwt.WithAudience(v.audience),
+		jwt.WithIssuer(v.issuer),
 		jwt.WithExpirationRequired())
 	if err != nil {
-		return fmt.Errorf("token validation failed: %w", err)
+		return nil, fmt.Errorf("token validation failed: %w", err)
 	}
 
 	if !token.Valid {
-		return fmt.Errorf("token is not valid")
+		return nil, fmt.Errorf("token is not valid")
 	}
 
 	err = v.validateCustomClaims(claims)
 	if err != nil {
-		return fmt.Errorf("token validation failed: %w", err)
+		return nil, fmt.Errorf("token validation failed: %w", err)
 	}
 
-	return nil
+	return claims, nil
+}
+
+// Authenticate RS256 bearer tokens of the validator issuer,
+// other tokens are left to the next authenticator of the chain
+func (v *JWTValidator) Authenticate(r *http.Request) (*Principal, error) {
+	tokenString, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := peekToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if alg := token.Method.Alg(); !strings.HasPrefix(alg, "RS") {
+		return nil, fmt.Errorf("%w: unexpected signing method %s", ErrNoCredentials, alg)
+	}
+	if issuer, _ := token.Claims.GetIssuer(); issuer != v.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %s", ErrNoCredentials, issuer)
+	}
+
+	ctx, span := tracer.Start(r.Context(), "auth.jwt.verify", trace.WithAttributes(attribute.String("jwt.issuer", v.issuer)))
+	defer span.End()
+
+	claims, err := v.parseToken(ctx, tokenString)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Scopes:  claims.Scopes(),
+		Method:  "jwt",
+	}, nil
+}
+
+// Read a token header and claims without verifying it
+func peekToken(tokenString string) (*jwt.Token, error) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a JWT: %w", ErrNoCredentials, err)
+	}
+	return token, nil
 }
@@ -1,18 +1,25 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/flmailla/resume/models"
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestJWTValidatorCreation(t *testing.T) {
@@ -64,7 +71,7 @@ func TestFetchJWKS(t *testing.T) {
 	defer server.Close()
 
 	validator := NewJWTValidator(server.URL)
-	jwks, err := validator.fetchJWKS()
+	jwks, err := validator.fetchJWKS(context.Background())
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -76,7 +83,7 @@ func TestFetchJWKS(t *testing.T) {
 	}
 
 	validator = NewJWTValidator("http://nonexistent")
-	_, err = validator.fetchJWKS()
+	_, err = validator.fetchJWKS(context.Background())
 	if err == nil {
 		t.Error("expected error for invalid URL, got none")
 	}
@@ -88,7 +95,7 @@ func TestFetchJWKS(t *testing.T) {
 	defer server.Close()
 
 	validator = NewJWTValidator(server.URL)
-	_, err = validator.fetchJWKS()
+	_, err = validator.fetchJWKS(context.Background())
 	if err == nil {
 		t.Error("expected error for non-200 status, got none")
 	}
@@ -151,7 +158,7 @@ func TestGetPublicKey(t *testing.T) {
 	defer server.Close()
 
 	validator := NewJWTValidator(server.URL)
-	key, err := validator.getPublicKey("key1")
+	key, err := validator.getPublicKey(context.Background(), "key1")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -159,7 +166,7 @@ func TestGetPublicKey(t *testing.T) {
 		t.Fatal("expected non-nil public key")
 	}
 
-	key, err = validator.getPublicKey("key1")
+	key, err = validator.getPublicKey(context.Background(), "key1")
 	if err != nil {
 		t.Fatalf("expected no error from cache, got %v", err)
 	}
@@ -167,7 +174,7 @@ func TestGetPublicKey(t *testing.T) {
 		t.Fatal("expected non-nil public key from cache")
 	}
 
-	_, err = validator.getPublicKey("key2")
+	_, err = validator.getPublicKey(context.Background(), "key2")
 	if err == nil {
 		t.Error("expected error for missing key, got none")
 	}
@@ -201,8 +208,8 @@ func TestVerifyToken(t *testing.T) {
 
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Audience:  jwt.ClaimStrings{aud},
-			Issuer:    iss,
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+			Issuer:    DefaultIssuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
 		},
 	}
@@ -236,3 +243,73 @@ func TestVerifyToken(t *testing.T) {
 		t.Error("expected error for expired token, got none")
 	}
 }
+
+func TestFetchJWKSPropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+
+	if _, err := NewJWTValidator(server.URL).fetchJWKS(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(traceparent, parent.TraceID().String()) {
+		t.Errorf("expected traceparent of trace %s, got %q", parent.TraceID(), traceparent)
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	sampleJWKS := JWKS{
+		Keys: []JWK{
+			{
+				Kty: "RSA",
+				Kid: "key1",
+				N:   base64.RawURLEncoding.EncodeToString([]byte("modulus")),
+				E:   base64.RawURLEncoding.EncodeToString([]byte{0x01, 0x00, 0x01}),
+			},
+		},
+	}
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(sampleJWKS)
+	}))
+	defer server.Close()
+
+	validator := NewJWTValidator(server.URL)
+	if err := validator.CheckKeys(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := validator.CheckKeys(context.Background()); err != nil {
+		t.Fatalf("expected no error from cache, got %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected the warm cache to be used, got %d fetches", fetches)
+	}
+
+	unreachable := NewJWTValidator("http://127.0.0.1:1/jwks.json")
+	if err := unreachable.CheckKeys(context.Background()); err == nil {
+		t.Error("expected error for unreachable JWKS, got none")
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JWKS{})
+	}))
+	defer empty.Close()
+
+	if err := NewJWTValidator(empty.URL).CheckKeys(context.Background()); !errors.Is(err, models.ErrJWKSNotWarm) {
+		t.Errorf("expected %v, got %v", models.ErrJWKSNotWarm, err)
+	}
+}
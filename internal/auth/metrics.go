@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/flmailla/resume/internal/metrics"
+	"github.com/flmailla/resume/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	authOutcomes = metrics.NewCounterVec("resume_auth_outcomes_total",
+		"Authentication attempts by outcome.", "outcome")
+	jwksFetches = metrics.NewCounterVec("resume_jwks_fetches_total",
+		"JWKS fetches by result.", "result")
+	jwksCacheAge = metrics.NewGaugeFunc("resume_jwks_cache_age_seconds",
+		"Age of the cached JWKS, NaN until it is first fetched.", func() float64 {
+			fetchedAt := jwksFetchedAt.Load()
+			if fetchedAt == 0 {
+				return math.NaN()
+			}
+			return time.Since(time.Unix(0, fetchedAt)).Seconds()
+		})
+)
+
+// Unix nanoseconds of the last successful JWKS fetch, zero before the first one
+var jwksFetchedAt atomic.Int64
+
+// Label of an authentication attempt result
+func authOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, models.ErrNoTokenSent):
+		return "missing_token"
+	case errors.Is(err, models.ErrNotBearer):
+		return "bad_prefix"
+	case errors.Is(err, models.ErrNoClientCert):
+		return "missing_certificate"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "expired"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid_signature"
+	case errors.Is(err, models.ErrTooManyRequests):
+		return "rate_limited"
+	case errors.Is(err, models.ErrAuthUnavailable):
+		return "error"
+	}
+	return "rejected"
+}
+
+// Count a JWKS fetch
+func recordJWKSFetch(err error) {
+	if err != nil {
+		jwksFetches.Inc("failure")
+		return
+	}
+	jwksFetches.Inc("success")
+	jwksFetchedAt.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flmailla/resume/internal/metrics"
+	"github.com/flmailla/resume/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "success", err: nil, want: "success"},
+		{name: "missing token", err: fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoTokenSent), want: "missing_token"},
+		{name: "bad prefix", err: fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNotBearer), want: "bad_prefix"},
+		{name: "missing certificate", err: fmt.Errorf("%w: %w", ErrNoCredentials, models.ErrNoClientCert), want: "missing_certificate"},
+		{name: "expired", err: fmt.Errorf("token validation failed: %w", jwt.ErrTokenExpired), want: "expired"},
+		{name: "invalid signature", err: fmt.Errorf("token validation failed: %w", jwt.ErrTokenSignatureInvalid), want: "invalid_signature"},
+		{name: "other", err: models.ErrAPIKeyRevoked, want: "rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authOutcome(tt.err); got != tt.want {
+				t.Errorf("expected outcome %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareCountsOutcomes(t *testing.T) {
+	handler := Chain{accepting("batch")}.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	var out strings.Builder
+	metrics.Default.WriteText(&out)
+
+	if !strings.Contains(out.String(), `resume_auth_outcomes_total{outcome="success"}`) {
+		t.Errorf("expected the success to be counted, got\n%s", out.String())
+	}
+}
+
+func TestJWKSFetchMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	NewJWTValidator(server.URL+"/broken").getPublicKey(context.Background(), "key1")
+	NewJWTValidator(server.URL).getPublicKey(context.Background(), "key1")
+
+	var out strings.Builder
+	metrics.Default.WriteText(&out)
+
+	for _, want := range []string{
+		`resume_jwks_fetches_total{result="failure"}`,
+		`resume_jwks_fetches_total{result="success"}`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in\n%s", want, out.String())
+		}
+	}
+
+	if strings.Contains(out.String(), "resume_jwks_cache_age_seconds NaN") {
+		t.Errorf("expected the cache age to be set after a successful fetch, got\n%s", out.String())
+	}
+}
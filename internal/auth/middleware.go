@@ -1,55 +1,97 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+
+	"github.com/flmailla/resume/logger"
+	"github.com/flmailla/resume/models"
 )
 
 // Middleware used by net/http
 // used to check the request authorization
-// and redirect to the right MUX handler afterwards
-func (v *JWTValidator) AuthMiddleware(mux http.Handler) http.Handler {
+// and redirect to the right MUX handler afterwards.
+// Routes matching one of the public patterns, written like
+// http.ServeMux patterns, are also served to anonymous callers.
+func (c Chain) AuthMiddleware(mux http.Handler, publicPatterns ...string) http.Handler {
+	public := newRouteMatcher(publicPatterns)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		if r.URL.Path == "/health" {
+		if unauthenticatedPaths[r.URL.Path] {
 			mux.ServeHTTP(w, r)
 			return
 		}
 
-		authHeader := r.Header.Get("Authorization")
+		principal, err := c.Authenticate(r)
+		authOutcomes.Inc(authOutcome(err))
+		if err != nil {
+			if isMissingCredentials(err) && public.match(r) {
+				mux.ServeHTTP(w, r)
+				return
+			}
 
-		if authHeader == "" {
 			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprint(w, ascii401)
+			if errors.Is(err, models.ErrAuthUnavailable) {
+				logger.FromContext(r.Context()).Error("Failed to authenticate the request", "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintln(w, http.StatusText(http.StatusInternalServerError))
+				return
+			}
+			var limited *FailuresLimitedError
+			if errors.As(err, &limited) {
+				limited.Decision.SetHeaders(w.Header())
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, ascii429)
+				return
+			}
+			if isMissingCredentials(err) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, ascii401)
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, ascii403)
 			return
 		}
 
-		const prefix = "Bearer "
-		if !strings.HasPrefix(authHeader, prefix) {
-			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprint(w, ascii401)
-			return
-		}
+		mux.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
 
-		token := strings.TrimPrefix(authHeader, prefix)
-		if token == "" {
-			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprint(w, ascii401)
-			return
-		}
+// Middleware relying on the JWT validator only
+func (v *JWTValidator) AuthMiddleware(mux http.Handler) http.Handler {
+	return Chain{v}.AuthMiddleware(mux)
+}
 
-		err := v.verifyToken(token)
-		if err != nil {
-			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-			w.WriteHeader(http.StatusForbidden)
-			fmt.Fprint(w, ascii403)
-			return
+// Set of routes, matched the same way http.ServeMux does
+type routeMatcher struct {
+	mux *http.ServeMux
+}
+
+func newRouteMatcher(patterns []string) *routeMatcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	m := &routeMatcher{mux: http.NewServeMux()}
+	registered := make(map[string]bool)
+	for _, pattern := range patterns {
+		if registered[pattern] {
+			continue
 		}
+		registered[pattern] = true
+		m.mux.Handle(pattern, http.NotFoundHandler())
+	}
+	return m
+}
 
-		mux.ServeHTTP(w, r)
-	})
+// Report whether the request targets one of the routes
+func (m *routeMatcher) match(r *http.Request) bool {
+	if m == nil {
+		return false
+	}
+	_, pattern := m.mux.Handler(r)
+	return pattern != ""
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -96,3 +97,84 @@ func TestAuthMiddlewareSpecificRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewarePublicRoutes(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		sentHeader     string
+		expectedStatus int
+		wantPrincipal  bool
+	}{
+		{
+			name:           "Anonymous call to a public route",
+			method:         "GET",
+			path:           "/profiles/1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Authenticated call to a public route",
+			method:         "GET",
+			path:           "/profiles/1",
+			sentHeader:     "Bearer valid",
+			expectedStatus: http.StatusOK,
+			wantPrincipal:  true,
+		},
+		{
+			name:           "Invalid token on a public route",
+			method:         "GET",
+			path:           "/profiles/1",
+			sentHeader:     "Bearer invalid",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Anonymous call with another method",
+			method:         "POST",
+			path:           "/profiles/1",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Anonymous call to a private route",
+			method:         "GET",
+			path:           "/profiles/1/experiences",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := Chain{&mockAuthenticator{authenticateFunc: func(r *http.Request) (*Principal, error) {
+				token, err := bearerToken(r)
+				if err != nil {
+					return nil, err
+				}
+				if token != "valid" {
+					return nil, errors.New("bad token")
+				}
+				return &Principal{Subject: "client"}, nil
+			}}}
+
+			var gotPrincipal *Principal
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPrincipal = PrincipalFromContext(r.Context())
+				w.Write([]byte("OK"))
+			})
+			middleware := chain.AuthMiddleware(nextHandler, "GET /profiles/{profile_id}")
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.sentHeader != "" {
+				req.Header.Set("Authorization", tt.sentHeader)
+			}
+			rr := httptest.NewRecorder()
+			middleware.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+
+			if (gotPrincipal != nil) != tt.wantPrincipal {
+				t.Errorf("expected principal %v, got %+v", tt.wantPrincipal, gotPrincipal)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/models"
+)
+
+// Error of a client refused after too many failed authentications
+type FailuresLimitedError struct {
+	Decision ratelimit.Decision
+}
+
+func (e *FailuresLimitedError) Error() string {
+	return models.ErrTooManyRequests.Error()
+}
+
+func (e *FailuresLimitedError) Unwrap() error {
+	return models.ErrTooManyRequests
+}
+
+// Authenticator refusing the addresses whose credentials were rejected
+// too often, before their credentials are even parsed. Only rejected
+// credentials take a token, requests sending none are not failures.
+// Addresses forwarded by the trusted proxies stand for their clients.
+func LimitFailures(next Authenticator, limiter *ratelimit.Limiter, proxies ratelimit.Proxies) Authenticator {
+	return &failureLimit{next: next, limiter: limiter, proxies: proxies}
+}
+
+type failureLimit struct {
+	next    Authenticator
+	limiter *ratelimit.Limiter
+	proxies ratelimit.Proxies
+}
+
+func (f *failureLimit) Authenticate(r *http.Request) (*Principal, error) {
+	addr := f.proxies.ClientAddr(r)
+	if decision := f.limiter.Peek(addr); !decision.Allowed {
+		return nil, &FailuresLimitedError{Decision: decision}
+	}
+
+	principal, err := f.next.Authenticate(r)
+	if err != nil && !isMissingCredentials(err) && !errors.Is(err, models.ErrAuthUnavailable) {
+		f.limiter.Take(addr)
+	}
+	return principal, err
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/models"
+)
+
+func TestLimitFailures(t *testing.T) {
+	calls := 0
+	validator := &mockAuthenticator{authenticateFunc: func(r *http.Request) (*Principal, error) {
+		calls++
+		switch r.Header.Get("Authorization") {
+		case "":
+			return nil, models.ErrNoTokenSent
+		case "Bearer valid":
+			return &Principal{Subject: "batch"}, nil
+		}
+		return nil, errors.New("invalid signature")
+	}}
+	limiter := ratelimit.New(2, time.Hour)
+	handler := Chain{LimitFailures(validator, limiter, nil)}.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+
+	send := func(authorization string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/skills", nil)
+		req.RemoteAddr = remoteAddr
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		remoteAddr    string
+		wantStatus    int
+		wantCalls     int
+	}{
+		{"missing token, not a failure", "", "192.0.2.1:1234", http.StatusUnauthorized, 1},
+		{"valid token", "Bearer valid", "192.0.2.1:1234", http.StatusOK, 2},
+		{"first failure", "Bearer forged", "192.0.2.1:1234", http.StatusForbidden, 3},
+		{"second failure", "Bearer forged", "192.0.2.1:1235", http.StatusForbidden, 4},
+		{"refused before parsing", "Bearer valid", "192.0.2.1:1236", http.StatusTooManyRequests, 4},
+		{"other address", "Bearer valid", "192.0.2.2:1234", http.StatusOK, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := send(tt.authorization, tt.remoteAddr)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d validations, got %d", tt.wantCalls, calls)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests {
+				if rec.Body.String() != ascii429 || rec.Header().Get("Retry-After") == "" {
+					t.Errorf("expected the 429 page with Retry-After, got %q", rec.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
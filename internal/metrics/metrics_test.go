@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounterVec("test_requests_total", "Requests.", "route", "status")
+	latency := reg.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.5, 0.1}, "route")
+	reg.NewGaugeFunc("test_age_seconds", "Age.", func() float64 { return math.NaN() })
+	reg.NewCounterFunc("test_waits_total", "Waits.", func() float64 { return 3 })
+
+	requests.Inc("GET /skills", "200")
+	requests.Inc("GET /skills", "200")
+	requests.Add(0.5, `GET /"quoted"`, "500")
+	latency.Observe(0.05, "GET /skills")
+	latency.Observe(0.3, "GET /skills")
+	latency.Observe(2, "GET /skills")
+
+	var out strings.Builder
+	if err := reg.WriteText(&out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := `# HELP test_age_seconds Age.
+# TYPE test_age_seconds gauge
+test_age_seconds NaN
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{route="GET /skills",le="0.1"} 1
+test_latency_seconds_bucket{route="GET /skills",le="0.5"} 2
+test_latency_seconds_bucket{route="GET /skills",le="+Inf"} 3
+test_latency_seconds_sum{route="GET /skills"} 2.35
+test_latency_seconds_count{route="GET /skills"} 3
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{route="GET /\"quoted\"",status="500"} 0.5
+test_requests_total{route="GET /skills",status="200"} 2
+# HELP test_waits_total Waits.
+# TYPE test_waits_total counter
+test_waits_total 3
+`
+	if out.String() != want {
+		t.Errorf("expected exposition\n%s\ngot\n%s", want, out.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("test_total", "Test.").Inc()
+
+	w := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != contentType {
+		t.Errorf("expected content type %s, got %s", contentType, ct)
+	}
+
+	if !strings.Contains(w.Body.String(), "test_total 1\n") {
+		t.Errorf("unexpected body %s", w.Body.String())
+	}
+}
+
+func TestRegistrationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(reg *Registry)
+	}{
+		{
+			name: "duplicate name",
+			fn: func(reg *Registry) {
+				reg.NewCounterVec("test_total", "Test.")
+				reg.NewCounterVec("test_total", "Test.")
+			},
+		},
+		{
+			name: "wrong label count",
+			fn: func(reg *Registry) {
+				reg.NewCounterVec("test_total", "Test.", "route").Inc()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic, got none")
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/logger"
+)
+
+// Middleware logging a line per request, with the route pattern
+// the mux matches and the principal the authentication resolves
+func AccessLog(mux *http.ServeMux) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, pattern := mux.Handler(r)
+
+			ctx, principal := auth.RecordPrincipal(r.Context())
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			subject := "anonymous"
+			if p := principal(); p != nil {
+				subject = p.Subject
+			}
+
+			logger.FromContext(r.Context()).Info("request",
+				"method", r.Method,
+				"route", pattern,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", rec.bytes,
+				"latency", time.Since(start),
+				"principal", subject,
+			)
+		})
+	}
+}
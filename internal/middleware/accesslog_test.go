@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/logger"
+)
+
+// Capture the logs as JSON lines
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	previous := logger.Logger
+	t.Cleanup(func() { logger.Logger = previous })
+
+	var buf bytes.Buffer
+	logger.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	return &buf
+}
+
+func TestAccessLog(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /profiles/{profile_id}", func(w http.ResponseWriter, r *http.Request) {
+		// Set down the chain, the way the auth middleware does
+		auth.WithPrincipal(r.Context(), &auth.Principal{Subject: "batch"})
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("GET /skills", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+
+	handler := Stack(mux, RequestID, AccessLog(mux))
+
+	tests := []struct {
+		name          string
+		path          string
+		wantRoute     string
+		wantStatus    float64
+		wantBytes     float64
+		wantPrincipal string
+	}{
+		{
+			name:          "authenticated request",
+			path:          "/profiles/1",
+			wantRoute:     "GET /profiles/{profile_id}",
+			wantStatus:    http.StatusCreated,
+			wantBytes:     5,
+			wantPrincipal: "batch",
+		},
+		{
+			name:          "anonymous request",
+			path:          "/skills",
+			wantRoute:     "GET /skills",
+			wantStatus:    http.StatusOK,
+			wantBytes:     2,
+			wantPrincipal: "anonymous",
+		},
+		{
+			name:          "unknown route",
+			path:          "/unknown",
+			wantRoute:     "",
+			wantStatus:    http.StatusNotFound,
+			wantBytes:     19,
+			wantPrincipal: "anonymous",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+
+			var entry map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to unmarshal access log %q: %v", logs.String(), err)
+			}
+
+			if entry["method"] != "GET" || entry["route"] != tt.wantRoute || entry["path"] != tt.path {
+				t.Errorf("unexpected request fields %v", entry)
+			}
+
+			if entry["status"] != tt.wantStatus || entry["bytes"] != tt.wantBytes {
+				t.Errorf("expected status %v and bytes %v, got %v and %v", tt.wantStatus, tt.wantBytes, entry["status"], entry["bytes"])
+			}
+
+			if entry["principal"] != tt.wantPrincipal {
+				t.Errorf("expected principal %s, got %v", tt.wantPrincipal, entry["principal"])
+			}
+
+			if entry["request_id"] == nil || entry["latency"] == nil {
+				t.Errorf("expected request_id and latency, got %v", entry)
+			}
+		})
+	}
+}
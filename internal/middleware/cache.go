@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+)
+
+// Conditional requests and in-memory caching of the GET responses of handlers.
+// Successful responses get a strong ETag computed from their body, and as
+// Last-Modified the time this server first served that body; a caller already
+// holding the representation is answered 304 Not Modified. When entries is
+// positive, the most recently used responses are kept in memory for ttl,
+// keyed by their URL and whether the caller is anonymous, anonymous
+// responses being redacted.
+type ResponseCache struct {
+	maxAge  time.Duration
+	ttl     time.Duration
+	entries int
+
+	mu    sync.Mutex
+	lru   *list.List // of *cachedResponse, the most recently used first
+	byKey map[string]*list.Element
+}
+
+type cachedResponse struct {
+	key      string
+	header   http.Header
+	body     []byte
+	etag     string
+	modified time.Time
+	expires  time.Time
+}
+
+func NewResponseCache(entries int, ttl time.Duration, maxAge time.Duration) *ResponseCache {
+	return &ResponseCache{
+		maxAge:  maxAge,
+		ttl:     ttl,
+		entries: entries,
+		lru:     list.New(),
+		byKey:   make(map[string]*list.Element),
+	}
+}
+
+// Drop the responses kept in memory, to be called once the data they were made of changes
+func (c *ResponseCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	clear(c.byKey)
+}
+
+// Middleware serving the responses of next through the cache
+func (c *ResponseCache) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anonymous := auth.PrincipalFromContext(r.Context()) == nil
+		visibility := "authenticated"
+		if anonymous {
+			visibility = "anonymous"
+		}
+		key := visibility + " " + r.URL.RequestURI()
+
+		now := time.Now()
+		entry, fresh := c.get(key, now)
+		if !fresh {
+			buffer := &bufferedResponse{header: make(http.Header)}
+			next.ServeHTTP(buffer, r)
+			if buffer.status != http.StatusOK {
+				buffer.flush(w)
+				return
+			}
+			entry = c.put(key, buffer, entry, now)
+		}
+		c.serve(w, r, entry, anonymous && !carriesCredentials(r))
+	})
+}
+
+// Whether the request carries credentials, valid or not, its response
+// then being kept from shared caches. Client certificates can't be varied
+// on, unlike the headers.
+func carriesCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != "" ||
+		(r.TLS != nil && len(r.TLS.PeerCertificates) > 0)
+}
+
+// Response of a key, along with whether it is still fresh. An expired response
+// is returned too, its Last-Modified being kept when the body did not change.
+func (c *ResponseCache) get(key string, now time.Time) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	entry := element.Value.(*cachedResponse)
+	return entry, now.Before(entry.expires)
+}
+
+func (c *ResponseCache) put(key string, response *bufferedResponse, previous *cachedResponse, now time.Time) *cachedResponse {
+	sum := sha256.Sum256(response.body.Bytes())
+	entry := &cachedResponse{
+		key:      key,
+		header:   response.header.Clone(),
+		body:     response.body.Bytes(),
+		etag:     `"` + hex.EncodeToString(sum[:16]) + `"`,
+		modified: now.UTC().Truncate(time.Second),
+		expires:  now.Add(c.ttl),
+	}
+	if previous != nil && previous.etag == entry.etag {
+		entry.modified = previous.modified
+	}
+	if c.entries <= 0 {
+		return entry
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.byKey[key]; ok {
+		c.lru.Remove(element)
+	}
+	c.byKey[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.entries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.byKey, oldest.Value.(*cachedResponse).key)
+	}
+	return entry
+}
+
+func (c *ResponseCache) serve(w http.ResponseWriter, r *http.Request, entry *cachedResponse, public bool) {
+	header := w.Header()
+	for name, values := range entry.header {
+		header[name] = values
+	}
+	header.Set("ETag", entry.etag)
+	header.Set("Last-Modified", entry.modified.Format(http.TimeFormat))
+	header.Set("Cache-Control", c.cacheControl(public))
+	header.Add("Vary", "Authorization")
+	header.Add("Vary", "X-API-Key")
+
+	if notModified(r, entry) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.body)
+}
+
+// Public responses may be kept by shared caches, the others by the caller only
+func (c *ResponseCache) cacheControl(public bool) string {
+	scope := "private"
+	if public {
+		scope = "public"
+	}
+	if c.maxAge <= 0 {
+		return scope + ", no-cache"
+	}
+	return fmt.Sprintf("%s, max-age=%d", scope, int(c.maxAge.Seconds()))
+}
+
+// Whether the caller holds the response, If-None-Match taking precedence
+// over If-Modified-Since as RFC 9110 requires
+func notModified(r *http.Request, entry *cachedResponse) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, etag := range strings.Split(match, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || etag == entry.etag {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !entry.modified.After(since)
+}
+
+// Response writer keeping the response, to be cached or flushed
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	for name, values := range b.header {
+		w.Header()[name] = values
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+)
+
+// Handler counting its calls, answering the body currently set
+type countingHandler struct {
+	calls  int
+	status int
+	body   string
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	w.Header().Set("Content-Type", "application/json")
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+	}
+	w.Write([]byte(h.body))
+}
+
+func get(handler http.Handler, path string, authenticated bool, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authenticated {
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "batch"}))
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCacheConditional(t *testing.T) {
+	next := &countingHandler{body: `{"id":1}`}
+	cache := NewResponseCache(0, time.Minute, time.Minute)
+	handler := cache.Handler(next)
+
+	first := get(handler, "/profiles/1", false)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != `{"id":1}` || len(etag) != 34 {
+		t.Fatalf("unexpected response %d %q with ETag %s", first.Code, first.Body.String(), etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("expected a public response, got %q", got)
+	}
+	if got := get(handler, "/profiles/1", true).Header().Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("expected a private response, got %q", got)
+	}
+	// Rejected on public routes, the credentials still keep the response private
+	if got := get(handler, "/profiles/1", false, "X-API-Key", "rsm_unknown").Header().Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("expected a private response with credentials, got %q", got)
+	}
+	if vary := first.Header().Values("Vary"); strings.Join(vary, ", ") != "Authorization, X-API-Key" {
+		t.Errorf("expected the response to vary on the credentials, got %v", vary)
+	}
+
+	tests := []struct {
+		name       string
+		header     []string
+		wantStatus int
+	}{
+		{"matching ETag", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"ETag among others", []string{"If-None-Match", `"stale", W/` + etag}, http.StatusNotModified},
+		{"any ETag", []string{"If-None-Match", "*"}, http.StatusNotModified},
+		{"stale ETag", []string{"If-None-Match", `"stale"`}, http.StatusOK},
+		{"ETag preferred to the date", []string{"If-None-Match", `"stale"`, "If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}, http.StatusOK},
+		{"no condition", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(handler, "/profiles/1", false, tt.header...)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("expected the ETag %s, got %s", etag, rec.Header().Get("ETag"))
+			}
+			if tt.wantStatus == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "") {
+				t.Errorf("expected no content, got %q (%s)", rec.Body.String(), rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	if next.calls != 3+len(tests) {
+		t.Errorf("expected every request to reach the handler without cache, got %d calls", next.calls)
+	}
+}
+
+func TestResponseCacheEntries(t *testing.T) {
+	next := &countingHandler{body: `[]`}
+	cache := NewResponseCache(2, time.Minute, 0)
+	handler := cache.Handler(next)
+
+	get(handler, "/skills", false)
+	rec := get(handler, "/skills", false)
+	if next.calls != 1 || rec.Body.String() != `[]` {
+		t.Fatalf("expected the cached response, got %d calls and %q", next.calls, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, no-cache" {
+		t.Errorf("expected a revalidated response, got %q", got)
+	}
+
+	// Anonymous responses are redacted, authenticated ones are kept apart
+	get(handler, "/skills", true)
+	if next.calls != 2 {
+		t.Errorf("expected the authenticated response to be cached apart, got %d calls", next.calls)
+	}
+
+	// The least recently used one is evicted
+	get(handler, "/skills?page=2", false)
+	get(handler, "/skills", false)
+	if next.calls != 4 {
+		t.Errorf("expected the first response to be evicted, got %d calls", next.calls)
+	}
+
+	next.body = `[{"id":1}]`
+	cache.Invalidate()
+	if rec := get(handler, "/skills", false); rec.Body.String() != next.body || next.calls != 5 {
+		t.Errorf("expected a fresh response once invalidated, got %q", rec.Body.String())
+	}
+
+	next.status = http.StatusInternalServerError
+	get(handler, "/profiles/404", false)
+	rec = get(handler, "/profiles/404", false)
+	if next.calls != 7 || rec.Code != http.StatusInternalServerError || rec.Header().Get("ETag") != "" {
+		t.Errorf("expected failures not to be cached, got %d calls, %d with ETag %q", next.calls, rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestResponseCacheLastModified(t *testing.T) {
+	next := &countingHandler{body: `[]`}
+	cache := NewResponseCache(8, time.Millisecond, time.Minute)
+	handler := cache.Handler(next)
+
+	first := get(handler, "/skills", false)
+	modified := first.Header().Get("Last-Modified")
+	if rec := get(handler, "/skills", false, "If-Modified-Since", modified); rec.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got %d", http.StatusNotModified, rec.Code)
+	}
+
+	// Expired then rendered again, an unchanged body keeps its date
+	time.Sleep(1100 * time.Millisecond)
+	if rec := get(handler, "/skills", false); rec.Header().Get("Last-Modified") != modified {
+		t.Errorf("expected Last-Modified %s once expired, got %s", modified, rec.Header().Get("Last-Modified"))
+	}
+
+	next.body = `[{"id":1}]`
+	time.Sleep(10 * time.Millisecond)
+	rec := get(handler, "/skills", false, "If-Modified-Since", modified)
+	if rec.Code != http.StatusOK || rec.Header().Get("Last-Modified") == modified {
+		t.Errorf("expected a changed body to be dated anew, got %d with Last-Modified %s", rec.Code, rec.Header().Get("Last-Modified"))
+	}
+}
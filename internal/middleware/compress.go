@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Writer of an encoding, reset for every response
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriter(nil) }},
+	"zstd": {New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+	"gzip": {New: func() any { return gzip.NewWriter(nil) }},
+}
+
+// Content encodings the responses can be compressed with
+func Encodings() []string {
+	return []string{"br", "zstd", "gzip"}
+}
+
+// Middleware compressing the responses with the encoding the client accepts
+// with the highest quality, ties going to the first of encodings. Only the
+// bodies of at least minSize bytes whose media type is listed in contentTypes,
+// e.g. application/json or text/*, are compressed. Those responses vary on
+// Accept-Encoding, and their ETag is weakened once compressed, the encoded
+// bytes differing from the representation the strong ETag stands for.
+func Compress(encodings []string, minSize int, contentTypes []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if len(encodings) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings),
+				head:           r.Method == http.MethodHead,
+				minSize:        minSize,
+				contentTypes:   contentTypes,
+			}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// Encoding of the response, none when the client accepts none of encodings
+func negotiateEncoding(accept string, encodings []string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// Response writer holding the body back until it reaches the minimum size,
+// then compressing it, or writing it as is when it ends before
+type compressWriter struct {
+	http.ResponseWriter
+	encoding     string
+	head         bool
+	minSize      int
+	contentTypes []string
+
+	status  int
+	buffer  []byte
+	started bool
+	encoder encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started || cw.status != 0 {
+		return
+	}
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	if !cw.compressible() {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if !cw.started {
+		if cw.status == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if !cw.started {
+			cw.buffer = append(cw.buffer, data...)
+			if len(cw.buffer) >= cw.minSize {
+				cw.start(cw.encoding != "")
+			}
+			return len(data), nil
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(data)
+	}
+	return cw.ResponseWriter.Write(data)
+}
+
+// Whether the body could be compressed, for a client accepting an encoding
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	switch {
+	case cw.head, cw.status == http.StatusNoContent, cw.status == http.StatusNotModified,
+		cw.status == http.StatusPartialContent, header.Get("Content-Encoding") != "":
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	family, _, _ := strings.Cut(mediaType, "/")
+	return slices.Contains(cw.contentTypes, mediaType) || slices.Contains(cw.contentTypes, family+"/*")
+}
+
+// Write the headers, then the body held back
+func (cw *compressWriter) start(compress bool) {
+	cw.started = true
+	header := cw.Header()
+	switch {
+	case cw.status == http.StatusNotModified:
+		// Validates the variant the client holds, compressed or not
+		header.Add("Vary", "Accept-Encoding")
+		if cw.encoding != "" {
+			weakenETag(header)
+		}
+	case cw.compressible():
+		header.Add("Vary", "Accept-Encoding")
+		if compress {
+			header.Set("Content-Encoding", cw.encoding)
+			header.Del("Content-Length")
+			weakenETag(header)
+			cw.encoder = encoders[cw.encoding].Get().(encoder)
+			cw.encoder.Reset(cw.ResponseWriter)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buffer) > 0 {
+		cw.Write(cw.buffer)
+		cw.buffer = nil
+	}
+}
+
+// Write what is held back and end the compressed stream
+func (cw *compressWriter) close() {
+	if !cw.started {
+		if cw.status == 0 {
+			return
+		}
+		cw.start(false)
+	}
+	if cw.encoder != nil {
+		cw.encoder.Close()
+		cw.encoder.Reset(io.Discard)
+		encoders[cw.encoding].Put(cw.encoder)
+		cw.encoder = nil
+	}
+}
+
+// Send what is written so far, compressed when it could be
+func (cw *compressWriter) Flush() {
+	if !cw.started && cw.status != 0 {
+		cw.start(cw.encoding != "")
+	}
+	if cw.encoder != nil {
+		cw.encoder.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Give http.ResponseController access to the deadlines
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func weakenETag(header http.Header) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+}
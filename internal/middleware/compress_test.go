@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br, zstd", "br"},
+		{"gzip;q=1.0, br;q=0.5", "gzip"},
+		{"br;q=0, zstd ; q=0.8", "zstd"},
+		{"*", "br"},
+		{"*;q=0.1, gzip;q=0.5", "gzip"},
+		{"identity", ""},
+		{"GZIP;q=abc", ""},
+		{"*;q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.accept, Encodings()); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+	var reader io.Reader
+	switch encoding {
+	case "":
+		reader = body
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(body)
+	case "zstd":
+		zr, err := zstd.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", encoding, err)
+	}
+	return string(decoded)
+}
+
+func TestCompress(t *testing.T) {
+	large := `{"description":"` + strings.Repeat("Designed and ran the integration platform. ", 64) + `"}`
+
+	tests := []struct {
+		name         string
+		method       string
+		accept       string
+		contentType  string
+		status       int
+		body         string
+		wantEncoding string
+		wantVary     bool
+	}{
+		{name: "brotli preferred", accept: "gzip, br, zstd", contentType: "application/json", body: large, wantEncoding: "br", wantVary: true},
+		{name: "zstd", accept: "zstd", contentType: "application/json; charset=utf-8", body: large, wantEncoding: "zstd", wantVary: true},
+		{name: "gzip", accept: "gzip", contentType: "text/plain", body: large, wantEncoding: "gzip", wantVary: true},
+		{name: "error response", accept: "gzip", contentType: "application/json", status: http.StatusNotFound, body: large, wantEncoding: "gzip", wantVary: true},
+		{name: "nothing accepted", accept: "", contentType: "application/json", body: large, wantVary: true},
+		{name: "below the minimum size", accept: "gzip", contentType: "application/json", body: `{"id":1}`, wantVary: true},
+		{name: "type not listed", accept: "gzip", contentType: "application/vnd.sqlite3", body: large},
+		{name: "no content", accept: "gzip", contentType: "application/json", status: http.StatusNoContent},
+		{name: "head request", method: http.MethodHead, accept: "gzip", contentType: "application/json", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(Encodings(), 256, []string{"application/json", "text/*"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("ETag", `"v1"`)
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				// Written in pieces, as encoders stream
+				for i := 0; i < len(tt.body); i += 100 {
+					w.Write([]byte(tt.body[i:min(i+100, len(tt.body))]))
+				}
+			}))
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/profiles/1/experiences", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			wantStatus := tt.status
+			if wantStatus == 0 {
+				wantStatus = http.StatusOK
+			}
+			if rec.Code != wantStatus {
+				t.Errorf("expected %d, got %d", wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("expected encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if got := rec.Header().Get("Vary") == "Accept-Encoding"; got != tt.wantVary {
+				t.Errorf("expected Vary on Accept-Encoding %v, got %q", tt.wantVary, rec.Header().Get("Vary"))
+			}
+			wantETag := `"v1"`
+			if tt.wantEncoding != "" {
+				wantETag = `W/"v1"`
+				if rec.Body.Len() >= len(tt.body) {
+					t.Errorf("expected a compressed body, got %d bytes out of %d", rec.Body.Len(), len(tt.body))
+				}
+			}
+			if got := rec.Header().Get("ETag"); got != wantETag {
+				t.Errorf("expected ETag %s, got %s", wantETag, got)
+			}
+			if got := decode(t, tt.wantEncoding, rec.Body); got != tt.body {
+				t.Errorf("expected the body back, got %q", got)
+			}
+		})
+	}
+}
+
+func TestCompressDisabled(t *testing.T) {
+	next := &countingHandler{}
+	if Compress(nil, 0, nil)(next) != http.Handler(next) {
+		t.Error("expected the handler to be left as is without encodings")
+	}
+}
+
+func TestCompressWithResponseCache(t *testing.T) {
+	next := &countingHandler{body: `[` + strings.Repeat(`{"id":1,"name":"PostgreSQL"},`, 40) + `{"id":2}]`}
+	handler := Compress(Encodings(), 256, []string{"application/json"})(NewResponseCache(8, time.Minute, time.Minute).Handler(next))
+
+	first := get(handler, "/skills", false, "Accept-Encoding", "gzip")
+	etag := first.Header().Get("ETag")
+	if first.Header().Get("Content-Encoding") != "gzip" || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected a gzip response with a weak ETag, got %q with %s", first.Header().Get("Content-Encoding"), etag)
+	}
+
+	// The weak ETag of the compressed variant validates the cached response
+	second := get(handler, "/skills", false, "Accept-Encoding", "gzip", "If-None-Match", etag)
+	if second.Code != http.StatusNotModified || second.Header().Get("ETag") != etag {
+		t.Errorf("expected %d with ETag %s, got %d with %s", http.StatusNotModified, etag, second.Code, second.Header().Get("ETag"))
+	}
+	if vary := second.Header().Values("Vary"); strings.Join(vary, ", ") != "Authorization, X-API-Key, Accept-Encoding" {
+		t.Errorf("expected the response to vary on both headers, got %v", vary)
+	}
+
+	identity := get(handler, "/skills", false)
+	if identity.Header().Get("Content-Encoding") != "" || identity.Header().Get("ETag") != strings.TrimPrefix(etag, "W/") {
+		t.Errorf("expected the strong ETag without compression, got %s", identity.Header().Get("ETag"))
+	}
+}
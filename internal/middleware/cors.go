@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cross-origin requests allowed to browsers. Origins are written like
+// https://resume.example.com, a * standing for any part of it, e.g.
+// https://*.example.com, or for any origin on its own. A * in the methods
+// or the headers allows any of them.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Middleware answering the CORS preflight requests itself, so that they
+// never reach the authentication, and allowing the origins of the policy
+// to read the responses. Disabled when no origin is allowed.
+func CORS(policy CORSPolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		if len(policy.AllowedOrigins) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != "" {
+				policy.preflight(w, r)
+				return
+			}
+			header := w.Header()
+			if !policy.anyOrigin() {
+				header.Add("Vary", "Origin")
+			}
+			if origin != "" && policy.allowOrigin(header, origin) && len(policy.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Answer a preflight request, without the CORS headers when the origin,
+// the method or one of the headers is not allowed, which the browser
+// takes as a refusal
+func (p CORSPolicy) preflight(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	if !p.anyOrigin() {
+		header.Add("Vary", "Origin")
+	}
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	var requested []string
+	for _, name := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			requested = append(requested, name)
+		}
+	}
+	allowed := listed(p.AllowedMethods, method, false)
+	for _, name := range requested {
+		allowed = allowed && listed(p.AllowedHeaders, name, true)
+	}
+
+	if allowed && p.allowOrigin(header, r.Header.Get("Origin")) {
+		header.Set("Access-Control-Allow-Methods", method)
+		if len(requested) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
+		if p.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Whether every origin is answered with *, which credentials forbid,
+// the responses not varying on Origin then
+func (p CORSPolicy) anyOrigin() bool {
+	return slices.Contains(p.AllowedOrigins, "*") && !p.AllowCredentials
+}
+
+// Set the allowed origin when origin matches the policy, echoed unless
+// any origin is allowed. With credentials, a lone * matches no origin,
+// lest every site could make credentialed requests.
+func (p CORSPolicy) allowOrigin(header http.Header, origin string) bool {
+	if p.anyOrigin() {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+	if !slices.ContainsFunc(p.AllowedOrigins, func(pattern string) bool {
+		return pattern != "*" && matchOrigin(pattern, origin)
+	}) {
+		return false
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if p.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// Whether origin matches pattern, its * standing for any non empty part
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(strings.ToLower(pattern), "*")
+	origin = strings.ToLower(origin)
+	if !wildcard {
+		return origin == prefix
+	}
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+func listed(list []string, value string, foldCase bool) bool {
+	return slices.ContainsFunc(list, func(item string) bool {
+		if foldCase {
+			return item == "*" || strings.EqualFold(item, value)
+		}
+		return item == "*" || item == value
+	})
+}
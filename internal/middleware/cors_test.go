@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"https://resume.example.com", "https://resume.example.com", true},
+		{"https://resume.example.com", "https://RESUME.example.com", true},
+		{"https://resume.example.com", "http://resume.example.com", false},
+		{"https://*.example.com", "https://app.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://app.example.com.evil.io", false},
+		{"http://localhost:*", "http://localhost:5173", true},
+		{"*", "null", true},
+	}
+	for _, tt := range tests {
+		if got := matchOrigin(tt.pattern, tt.origin); got != tt.want {
+			t.Errorf("matchOrigin(%q, %q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"ETag", "Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		header      []string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "preflight",
+			method:     http.MethodOptions,
+			header:     []string{"Origin", "https://app.example.com", "Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "authorization,content-type"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "POST",
+				"Access-Control-Allow-Headers":     "authorization, content-type",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:        "preflight of an unknown origin",
+			method:      http.MethodOptions,
+			header:      []string{"Origin", "https://evil.io", "Access-Control-Request-Method", "GET"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:        "preflight of a method not allowed",
+			method:      http.MethodOptions,
+			header:      []string{"Origin", "https://app.example.com", "Access-Control-Request-Method", "DELETE"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:        "preflight of a header not allowed",
+			method:      http.MethodOptions,
+			header:      []string{"Origin", "https://app.example.com", "Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "x-debug"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Headers": ""},
+		},
+		{
+			name:       "cross-origin request",
+			method:     http.MethodGet,
+			header:     []string{"Origin", "https://app.example.com"},
+			wantStatus: http.StatusUnauthorized,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "ETag, Retry-After",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:        "request of an unknown origin",
+			method:      http.MethodGet,
+			header:      []string{"Origin", "https://evil.io"},
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Expose-Headers": "", "Vary": "Origin"},
+		},
+		{
+			name:        "options without preflight",
+			method:      http.MethodOptions,
+			header:      []string{"Origin", "https://app.example.com"},
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://app.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Stands for the authentication, which preflights must not reach
+			handler := CORS(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			req := httptest.NewRequest(tt.method, "/profiles/1/experiences", nil)
+			for i := 0; i < len(tt.header); i += 2 {
+				req.Header.Set(tt.header[i], tt.header[i+1])
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			for name, value := range tt.wantHeaders {
+				if got := rec.Header().Get(name); got != value {
+					t.Errorf("expected %s: %q, got %q", name, value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	handler := CORS(CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"*"}})(&countingHandler{})
+
+	req := httptest.NewRequest(http.MethodOptions, "/skills", nil)
+	req.Header.Set("Origin", "https://resume.dev")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" || rec.Header().Get("Access-Control-Allow-Methods") != http.MethodPut {
+		t.Errorf("expected any origin and method, got %v", rec.Header())
+	}
+	if vary := rec.Header().Values("Vary"); slices.Contains(vary, "Origin") {
+		t.Errorf("expected the response not to vary on Origin, got %v", vary)
+	}
+}
+
+func TestCORSAnyOriginWithCredentials(t *testing.T) {
+	handler := CORS(CORSPolicy{
+		AllowedOrigins:   []string{"*", "https://resume.example.com"},
+		AllowCredentials: true,
+	})(&countingHandler{})
+
+	for origin, want := range map[string]string{
+		"https://evil.io":            "",
+		"https://resume.example.com": "https://resume.example.com",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/skills", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("%s: expected allowed origin %q, got %q", origin, want, got)
+		}
+	}
+}
+
+func TestCORSDisabled(t *testing.T) {
+	next := &countingHandler{}
+	if CORS(CORSPolicy{})(next) != http.Handler(next) {
+		t.Error("expected the handler to be left as is without origins")
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/flmailla/resume/models"
+)
+
+// Limits applied to the requests of a route, a zero value keeps the server one
+type RouteLimit struct {
+	Pattern      string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	MaxBodyBytes int64
+}
+
+// Middleware limiting the size of the request bodies, and overriding
+// the server deadlines and body limit of the routes matching a pattern,
+// written like http.ServeMux patterns
+func Limits(maxBodyBytes int64, routes []RouteLimit) Middleware {
+	mux := http.NewServeMux()
+	overrides := make(map[string]RouteLimit)
+	for _, route := range routes {
+		if _, exists := overrides[route.Pattern]; !exists {
+			mux.Handle(route.Pattern, http.NotFoundHandler())
+		}
+		overrides[route.Pattern] = route
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := RouteLimit{MaxBodyBytes: maxBodyBytes}
+			if _, pattern := mux.Handler(r); pattern != "" {
+				limit = limit.override(overrides[pattern])
+			}
+
+			// Deadlines are not supported by every writer, e.g. in tests
+			rc := http.NewResponseController(w)
+			if limit.ReadTimeout > 0 {
+				rc.SetReadDeadline(time.Now().Add(limit.ReadTimeout))
+			}
+			if limit.WriteTimeout > 0 {
+				rc.SetWriteDeadline(time.Now().Add(limit.WriteTimeout))
+			}
+
+			if limit.MaxBodyBytes > 0 {
+				if r.ContentLength > limit.MaxBodyBytes {
+					writeBodyTooLarge(w)
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limit.MaxBodyBytes)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Limits of a route, falling back on the given ones
+func (l RouteLimit) override(route RouteLimit) RouteLimit {
+	if route.ReadTimeout > 0 {
+		l.ReadTimeout = route.ReadTimeout
+	}
+	if route.WriteTimeout > 0 {
+		l.WriteTimeout = route.WriteTimeout
+	}
+	if route.MaxBodyBytes > 0 {
+		l.MaxBodyBytes = route.MaxBodyBytes
+	}
+	return l
+}
+
+func writeBodyTooLarge(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error:   models.ErrBodyTooLarge.Error(),
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "The request body exceeds the size allowed on this route",
+	})
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Handler reading the whole body
+func readBodyHandler(w http.ResponseWriter, r *http.Request) {
+	var maxBytesErr *http.MaxBytesError
+	if _, err := io.ReadAll(r.Body); errors.As(err, &maxBytesErr) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestLimitsBody(t *testing.T) {
+	handler := Limits(8, []RouteLimit{
+		{Pattern: "POST /uploads", MaxBodyBytes: 32},
+	})(http.HandlerFunc(readBodyHandler))
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{
+			name:           "body within the limit",
+			path:           "/profiles",
+			body:           "small",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "declared body over the limit",
+			path:           "/profiles",
+			body:           "way too large",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "streamed body over the limit",
+			path:           "/profiles",
+			body:           "way too large",
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "route allowing larger bodies",
+			path:           "/uploads",
+			body:           "way too large",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "route limit exceeded",
+			path:           "/uploads",
+			body:           strings.Repeat("x", 33),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestLimitsWriteTimeout(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("rendered"))
+	})
+
+	ts := httptest.NewUnstartedServer(Limits(0, []RouteLimit{
+		{Pattern: "GET /profiles/{profile_id}/pdf", WriteTimeout: time.Second},
+	})(slow))
+	ts.Config.WriteTimeout = 50 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{
+			name:    "server write timeout",
+			path:    "/profiles/1",
+			wantErr: true,
+		},
+		{
+			name:    "route with a longer write timeout",
+			path:    "/profiles/1/pdf",
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flmailla/resume/internal/metrics"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("resume_http_requests_total",
+		"HTTP requests by route pattern and status.", "route", "status")
+	httpDuration = metrics.NewHistogramVec("resume_http_request_duration_seconds",
+		"HTTP request latency by route pattern and status.", metrics.DefaultBuckets, "route", "status")
+)
+
+// Middleware counting the requests and observing their latency,
+// labeled by the route pattern the mux matches and the response status
+func Metrics(mux *http.ServeMux) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			httpRequests.Inc(route, strconv.Itoa(status))
+			httpDuration.Observe(time.Since(start).Seconds(), route, strconv.Itoa(status))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flmailla/resume/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /experiences/{experience_id}/skills", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := Metrics(mux)(mux)
+
+	for _, path := range []string{"/experiences/1/skills", "/experiences/2/skills", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	var out strings.Builder
+	metrics.Default.WriteText(&out)
+
+	for _, want := range []string{
+		`resume_http_requests_total{route="GET /experiences/{experience_id}/skills",status="418"} 2`,
+		`resume_http_requests_total{route="unmatched",status="404"} 1`,
+		`resume_http_request_duration_seconds_count{route="GET /experiences/{experience_id}/skills",status="418"} 2`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in\n%s", want, out.String())
+		}
+	}
+}
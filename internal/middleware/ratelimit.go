@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/models"
+)
+
+// Requests a client can make over a period to the routes matching a pattern,
+// written like http.ServeMux patterns
+type RouteRateLimit struct {
+	Pattern  string
+	Requests int
+	Period   time.Duration
+}
+
+// Middleware limiting the requests of every client, identified by its
+// principal or, when anonymous, by its address. The routes matching a
+// pattern have buckets of their own, the others sharing the default one,
+// disabled when requests is not positive. Addresses forwarded by the
+// trusted proxies stand for their clients. To be placed after the
+// authentication, the principal being set by it.
+func RateLimit(requests int, period time.Duration, routes []RouteRateLimit, proxies ratelimit.Proxies) Middleware {
+	var fallback *ratelimit.Limiter
+	if requests > 0 {
+		fallback = ratelimit.New(requests, period)
+	}
+	mux := http.NewServeMux()
+	limiters := make(map[string]*ratelimit.Limiter)
+	for _, route := range routes {
+		if _, exists := limiters[route.Pattern]; !exists {
+			mux.Handle(route.Pattern, http.NotFoundHandler())
+		}
+		limiters[route.Pattern] = ratelimit.New(route.Requests, route.Period)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := fallback
+			if _, pattern := mux.Handler(r); pattern != "" {
+				limiter = limiters[pattern]
+			}
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "address:" + proxies.ClientAddr(r)
+			if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+				key = "principal:" + principal.Method + ":" + principal.Subject
+			}
+			decision := limiter.Take(key)
+			decision.SetHeaders(w.Header())
+			if !decision.Allowed {
+				writeTooManyRequests(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeTooManyRequests(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error:   models.ErrTooManyRequests.Error(),
+		Code:    http.StatusTooManyRequests,
+		Message: "The rate limit of this client is exceeded, retry later",
+	})
+}
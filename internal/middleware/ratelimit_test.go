@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/models"
+)
+
+func TestRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /skills", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /admin/backup", func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimit(2, time.Hour, []RouteRateLimit{
+		{Pattern: "GET /admin/backup", Requests: 1, Period: time.Hour},
+	}, nil)(mux)
+
+	send := func(path string, method string, subject string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		if subject != "" {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Method: method}))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		method        string
+		subject       string
+		remoteAddr    string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{"first request", "/skills", "jwt", "batch", "192.0.2.1:1234", http.StatusOK, "1"},
+		{"same principal, other address", "/skills", "jwt", "batch", "192.0.2.2:1234", http.StatusOK, "0"},
+		{"principal limited", "/skills", "jwt", "batch", "192.0.2.1:1234", http.StatusTooManyRequests, "0"},
+		{"same subject, other method", "/skills", "apikey", "batch", "192.0.2.1:1234", http.StatusOK, "1"},
+		{"route counted apart", "/admin/backup", "jwt", "batch", "192.0.2.1:1234", http.StatusOK, "0"},
+		{"route limited", "/admin/backup", "jwt", "batch", "192.0.2.1:1234", http.StatusTooManyRequests, "0"},
+		{"other principal", "/skills", "jwt", "ci", "192.0.2.1:1234", http.StatusOK, "1"},
+		{"anonymous by address", "/skills", "", "", "192.0.2.1:1234", http.StatusOK, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := send(tt.path, tt.method, tt.subject, tt.remoteAddr)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("expected %s remaining, got %q", tt.wantRemaining, got)
+			}
+			if tt.wantStatus != http.StatusTooManyRequests {
+				return
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("expected a Retry-After header")
+			}
+			var body models.ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != http.StatusTooManyRequests {
+				t.Errorf("expected a JSON error, got %+v (%v)", body, err)
+			}
+		})
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	handler := RateLimit(0, 0, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/skills", nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("expected no limit, got %d with %q", rec.Code, rec.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestRateLimitBehindProxy(t *testing.T) {
+	proxies, _ := ratelimit.ParseProxies([]string{"10.0.0.1"})
+	handler := RateLimit(1, time.Hour, nil, proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/skills", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if code := send("203.0.113.2"); code != http.StatusOK {
+		t.Errorf("expected another client of the proxy to have its own bucket, got %d", code)
+	}
+	if code := send("203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Errorf("expected the first client to be limited, got %d", code)
+	}
+}
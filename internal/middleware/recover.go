@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/flmailla/resume/logger"
+)
+
+// Problem details response (RFC 9457)
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Middleware turning a panic into a 500 problem response, the stack being logged
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			// Let net/http abort the response as intended
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			logger.FromContext(r.Context()).Error("Recovered from panic",
+				"error", err,
+				"stack", string(debug.Stack()),
+			)
+
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(problem{
+				Type:      "about:blank",
+				Title:     http.StatusText(http.StatusInternalServerError),
+				Status:    http.StatusInternalServerError,
+				RequestID: RequestIDFromContext(r.Context()),
+			})
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
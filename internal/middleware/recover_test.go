@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	logs := captureLogs(t)
+	handler := Stack(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var profile map[string]string
+		profile["name"] = "nil map"
+	}), RequestID, Recover)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected a problem response, got %s", ct)
+	}
+
+	var got problem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response body: %v", err)
+	}
+	if got.Status != http.StatusInternalServerError || got.RequestID != "req-1" {
+		t.Errorf("unexpected problem %+v", got)
+	}
+
+	if !strings.Contains(logs.String(), "recover_test.go") || !strings.Contains(logs.String(), `"request_id":"req-1"`) {
+		t.Errorf("expected the stack to be logged with the request ID, got %s", logs.String())
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be propagated, got %v", err)
+		}
+	}()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantKept bool
+	}{
+		{
+			name:     "propagated from the caller",
+			header:   "gateway-4f2a",
+			wantKept: true,
+		},
+		{
+			name:     "generated when missing",
+			header:   "",
+			wantKept: false,
+		},
+		{
+			name:     "regenerated when unsafe",
+			header:   "id\twith control",
+			wantKept: false,
+		},
+		{
+			name:     "regenerated when too long",
+			header:   strings.Repeat("x", maxRequestIDLength+1),
+			wantKept: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if got == "" || w.Header().Get(requestIDHeader) != got {
+				t.Errorf("expected the response header to echo the request ID %q, got %q", got, w.Header().Get(requestIDHeader))
+			}
+
+			if (got == tt.header) != tt.wantKept {
+				t.Errorf("expected kept %v, got request ID %q for header %q", tt.wantKept, got, tt.header)
+			}
+		})
+	}
+}
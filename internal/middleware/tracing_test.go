@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	mux := http.NewServeMux()
+	var handlerSpan trace.SpanContext
+	mux.HandleFunc("GET /profiles/{profile_id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	handler := Stack(mux, RequestID, Tracing(mux))
+
+	req := httptest.NewRequest("GET", "/profiles/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "GET /profiles/{profile_id}" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("unexpected span %s of kind %v", span.Name(), span.SpanKind())
+	}
+
+	if span.Parent().SpanID().String() != "00f067aa0ba902b7" || span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the span to continue the caller trace, got parent %v", span.Parent())
+	}
+
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Error("expected the span to be available to the handler")
+	}
+
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected an error status for a 502, got %v", span.Status())
+	}
+
+	wantStatus := attribute.Int("http.response.status_code", http.StatusBadGateway)
+	found := false
+	for _, attr := range span.Attributes() {
+		found = found || attr == wantStatus
+	}
+	if !found {
+		t.Errorf("expected attribute %v, got %v", wantStatus, span.Attributes())
+	}
+}
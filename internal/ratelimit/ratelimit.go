@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token buckets keyed by client, each holding up to requests tokens
+// and refilled at requests per period. Buckets refilled to the brim
+// are dropped, so that idle clients cost nothing.
+type Limiter struct {
+	requests int
+	period   time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Outcome of a request against the bucket of its client
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next token, when not allowed
+	RetryAfter time.Duration
+	Period     time.Duration
+}
+
+func New(requests int, period time.Duration) *Limiter {
+	return &Limiter{
+		requests: requests,
+		period:   period,
+		now:      time.Now,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Take a token from the bucket of key, the request being allowed when one was left
+func (l *Limiter) Take(key string) Decision {
+	return l.decide(key, true)
+}
+
+// State of the bucket of key, without taking a token
+func (l *Limiter) Peek(key string) Decision {
+	return l.decide(key, false)
+}
+
+func (l *Limiter) decide(key string, take bool) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.requests), last: now}
+	} else {
+		b.tokens = l.refill(b, now)
+		b.last = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed && take {
+		b.tokens--
+	}
+	if take || ok {
+		l.buckets[key] = b
+	}
+
+	rate := float64(l.requests) / l.period.Seconds()
+	decision := Decision{
+		Allowed:   allowed,
+		Limit:     l.requests,
+		Remaining: int(b.tokens),
+		Reset:     time.Duration((float64(l.requests) - b.tokens) / rate * float64(time.Second)),
+		Period:    l.period,
+	}
+	if !allowed {
+		decision.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	return decision
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	rate := float64(l.requests) / l.period.Seconds()
+	return math.Min(float64(l.requests), b.tokens+now.Sub(b.last).Seconds()*rate)
+}
+
+// Drop the full buckets, once per period
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.requests) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Describe the decision with the RateLimit header fields of the IETF draft,
+// and Retry-After when the request is refused
+func (d Decision) SetHeaders(header http.Header) {
+	header.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", d.Limit, seconds(d.Period)))
+	if !d.Allowed {
+		header.Set("Retry-After", strconv.Itoa(max(seconds(d.RetryAfter), 1)))
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Address of the client of a request, as seen on the connection
+func ClientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Reverse proxies trusted to forward the address of their client
+type Proxies []netip.Prefix
+
+// Parse the proxies, each an address or a CIDR prefix
+func ParseProxies(list []string) (Proxies, error) {
+	var proxies Proxies
+	for _, item := range list {
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither an address nor a CIDR prefix", item)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+func (p Proxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return slices.ContainsFunc(p, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+// Address of the client of a request. Connections of a trusted proxy are
+// attributed to the address it forwards: the last of X-Forwarded-For which
+// is not a trusted proxy itself, or X-Real-IP when there is no such header.
+// Headers of any other peer are ignored, clients could forge them.
+func (p Proxies) ClientAddr(r *http.Request) string {
+	peer := ClientAddr(r)
+	if addr, err := netip.ParseAddr(peer); err != nil || !p.trusts(addr) {
+		return peer
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return addr.Unmap().String()
+		}
+		return peer
+	}
+	client := peer
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap().String()
+		if !p.trusts(addr) {
+			break
+		}
+	}
+	return client
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	limiter := New(3, 3*time.Second)
+	limiter.now = func() time.Time { return now }
+
+	if peek := limiter.Peek("batch"); !peek.Allowed || peek.Remaining != 3 {
+		t.Errorf("expected a full bucket, got %+v", peek)
+	}
+	for i := 2; i >= 0; i-- {
+		if decision := limiter.Take("batch"); !decision.Allowed || decision.Remaining != i {
+			t.Fatalf("expected %d remaining, got %+v", i, decision)
+		}
+	}
+
+	refused := limiter.Take("batch")
+	if refused.Allowed || refused.RetryAfter != time.Second || refused.Reset != 3*time.Second {
+		t.Errorf("expected a refusal for a second, got %+v", refused)
+	}
+	if other := limiter.Take("anonymous"); !other.Allowed {
+		t.Error("expected every client to have its own bucket")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if decision := limiter.Take("batch"); !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("expected a token refilled, got %+v", decision)
+	}
+
+	// Full again, the buckets are dropped on the next sweep
+	now = now.Add(time.Minute)
+	limiter.Peek("batch")
+	if len(limiter.buckets) != 0 {
+		t.Errorf("expected the full buckets to be dropped, got %d", len(limiter.buckets))
+	}
+}
+
+func TestDecisionSetHeaders(t *testing.T) {
+	header := http.Header{}
+	Decision{Limit: 10, Remaining: 0, Reset: 59500 * time.Millisecond, RetryAfter: 200 * time.Millisecond, Period: time.Minute}.SetHeaders(header)
+
+	want := map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"RateLimit-Policy":    "10;w=60",
+		"Retry-After":         "1",
+	}
+	for name, value := range want {
+		if got := header.Get(name); got != value {
+			t.Errorf("expected %s: %s, got %q", name, value, got)
+		}
+	}
+}
+
+func TestClientAddr(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "[2001:db8::1]:51234"
+	if got := ClientAddr(req); got != "2001:db8::1" {
+		t.Errorf("ClientAddr() = %q", got)
+	}
+}
+
+func TestProxiesClientAddr(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{"direct client", "198.51.100.7:1234", nil, "198.51.100.7"},
+		{"forged header of a direct client", "198.51.100.7:1234", map[string][]string{"X-Forwarded-For": {"203.0.113.1"}}, "198.51.100.7"},
+		{"client of a trusted proxy", "10.1.2.3:1234", map[string][]string{"X-Forwarded-For": {"203.0.113.1"}}, "203.0.113.1"},
+		{"spoofed first hop", "10.1.2.3:1234", map[string][]string{"X-Forwarded-For": {"1.1.1.1, 203.0.113.1"}}, "203.0.113.1"},
+		{"chain of trusted proxies", "10.1.2.3:1234", map[string][]string{"X-Forwarded-For": {"203.0.113.1, 192.0.2.10", "10.9.9.9"}}, "203.0.113.1"},
+		{"invalid hop", "10.1.2.3:1234", map[string][]string{"X-Forwarded-For": {"unknown, 10.9.9.9"}}, "10.9.9.9"},
+		{"real ip of a trusted proxy", "192.0.2.10:1234", map[string][]string{"X-Real-IP": {"203.0.113.1"}}, "203.0.113.1"},
+		{"trusted proxy without header", "[::ffff:10.1.2.3]:1234", nil, "::ffff:10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+			if got := proxies.ClientAddr(req); got != tt.want {
+				t.Errorf("ClientAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseProxies([]string{"proxy.internal"}); err == nil {
+		t.Error("expected a host name to be refused")
+	}
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Build a span exporter, the endpoint being empty when not configured
+type ExporterFactory func(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error)
+
+var (
+	mu        sync.Mutex
+	exporters = map[string]ExporterFactory{
+		"stdout": newStdoutExporter,
+		"otlp":   newOTLPExporter,
+	}
+)
+
+// Make an exporter selectable by name, replacing any previous one
+func RegisterExporter(name string, factory ExporterFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	exporters[name] = factory
+}
+
+// Names of the selectable exporters, "none" disabling the export
+func Exporters() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := []string{"none"}
+	for name := range exporters {
+		names = append(names, name)
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// Install the W3C trace context propagator and, unless the exporter
+// is "none" or empty, a tracer provider exporting the spans.
+// The returned function flushes and stops the export.
+func Setup(ctx context.Context, exporter string, endpoint string, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if exporter == "" || exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	mu.Lock()
+	factory, exists := exporters[exporter]
+	mu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown span exporter %q", exporter)
+	}
+
+	spanExporter, err := factory(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s span exporter: %w", exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newStdoutExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+}
+
+// OTLP over HTTP, the endpoint being the full traces URL
+// (e.g. http://localhost:4318/v1/traces), or read from the
+// OTEL_EXPORTER_OTLP_* variables when empty
+func newOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	var options []otlptracehttp.Option
+	if endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	return otlptracehttp.New(ctx, options...)
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter keeping the names of the exported spans
+type recordingExporter struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, span := range spans {
+		e.names = append(e.names, span.Name())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestSetup(t *testing.T) {
+	exporter := &recordingExporter{}
+	RegisterExporter("memory", func(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+		return exporter, nil
+	})
+
+	if !slices.Equal(Exporters(), []string{"none", "memory", "otlp", "stdout"}) {
+		t.Errorf("unexpected exporters %v", Exporters())
+	}
+
+	if _, err := Setup(context.Background(), "jaeger", "", "resume"); err == nil {
+		t.Error("expected error for an unknown exporter, got none")
+	}
+
+	shutdown, err := Setup(context.Background(), "none", "", "resume")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	shutdown, err = Setup(context.Background(), "memory", "", "resume")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if !slices.Equal(exporter.names, []string{"operation"}) {
+		t.Errorf("expected the span to be flushed on shutdown, got %v", exporter.names)
+	}
+}
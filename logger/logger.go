@@ -1,38 +1,122 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Logger *slog.Logger
 
-const loggerPath string = "/var/log/resume/app.log"
+var logRotator *lumberjack.Logger
 
-func InitLogger() error {
+// Level of the global logger, changed at runtime by SetLevel
+var level = new(slog.LevelVar)
 
-	logRotator := &lumberjack.Logger{
-		Filename:   loggerPath,
-		MaxSize:    100,
-		MaxBackups: 5,
-		MaxAge:     30,
-		Compress:   true,
+// Settings of the global logger
+type Options struct {
+	Level      string // debug, info, warn or error
+	Format     string // json or text
+	Stdout     bool
+	File       string // rotated log file, none when empty
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// Log on stdout and/or in a rotated file, sensitive attributes redacted
+func InitLogger(opts Options) error {
+	if err := SetLevel(opts.Level); err != nil {
+		return err
 	}
 
-	multiWriter := slog.NewJSONHandler(
-		io.MultiWriter(os.Stdout, logRotator),
-		&slog.HandlerOptions{
-			AddSource: true,
-			Level:     slog.LevelInfo,
-		},
-	)
+	var writers []io.Writer
+	if opts.Stdout {
+		writers = append(writers, os.Stdout)
+	}
+	if opts.File != "" {
+		logRotator = &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+		}
+		writers = append(writers, logRotator)
+	}
 
-	Logger = slog.New(multiWriter)
+	handlerOptions := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case "json", "":
+		handler = slog.NewJSONHandler(io.MultiWriter(writers...), handlerOptions)
+	case "text":
+		handler = slog.NewTextHandler(io.MultiWriter(writers...), handlerOptions)
+	default:
+		return fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	Logger = slog.New(handler)
 
 	slog.SetDefault(Logger)
 
 	return nil
 }
+
+// Parse a level name, case insensitive, e.g. debug or WARN
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+	return l, nil
+}
+
+// Change the level of the global logger, effective immediately
+func SetLevel(name string) error {
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// Current level of the global logger, in lower case
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// Close the log file, once nothing logs anymore
+func CloseLogger() error {
+	if logRotator == nil {
+		return nil
+	}
+	return logRotator.Close()
+}
+
+type loggerKey struct{}
+
+// Attach a request-scoped logger to a context
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// Return the request-scoped logger, or the global one
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Logger
+}
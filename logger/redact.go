@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Replacement of the redacted values
+const redacted string = "[REDACTED]"
+
+// Attributes never logged in clear, compared case insensitively
+var sensitiveKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+\.)+[A-Za-z]{2,}`)
+
+// Hide the credentials and the local part of the email addresses
+// of an attribute, used as slog.HandlerOptions.ReplaceAttr
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redactEmails(a.Value.String()))
+	case slog.KindAny:
+		if header, ok := a.Value.Any().(http.Header); ok {
+			a.Value = slog.AnyValue(redactHeader(header))
+		}
+	}
+	return a
+}
+
+// Replace the local part of the email addresses, the domain being kept
+func redactEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		return "***" + email[strings.LastIndex(email, "@"):]
+	})
+}
+
+func redactHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	for key := range clone {
+		if sensitiveKeys[strings.ToLower(key)] {
+			clone[key] = []string{redacted}
+		}
+	}
+	return clone
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact}))
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-token")
+	header.Set("Accept", "application/json")
+
+	l.With("authorization", "Bearer secret-token").
+		WithGroup("request").
+		Info("Profile requested by jane.doe@example.com",
+			"Cookie", "session=secret",
+			"header", header,
+			"contact", "Contact: john+cv@mail.example.org",
+			"path", "/profiles/1")
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected the credentials to be redacted, got %s", buf.String())
+	}
+	for _, email := range []string{"jane.doe@", "john+cv@"} {
+		if strings.Contains(buf.String(), email) {
+			t.Errorf("expected %s to be redacted, got %s", email, buf.String())
+		}
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to unmarshal the record: %v", err)
+	}
+	if got := record["msg"]; got != "Profile requested by ***@example.com" {
+		t.Errorf("expected the email domain to be kept, got %q", got)
+	}
+	request := record["request"].(map[string]any)
+	if got := request["contact"]; got != "Contact: ***@mail.example.org" {
+		t.Errorf("expected the email domain to be kept, got %q", got)
+	}
+	if got := request["path"]; got != "/profiles/1" {
+		t.Errorf("expected the other attributes untouched, got %q", got)
+	}
+	if got := request["header"].(map[string]any)["Accept"].([]any)[0]; got != "application/json" {
+		t.Errorf("expected the other headers untouched, got %q", got)
+	}
+	if header.Get("Authorization") != "Bearer secret-token" {
+		t.Error("expected the logged header not to be modified")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	t.Cleanup(func() { SetLevel("info") })
+
+	if err := SetLevel("WARN"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if Level() != "warn" {
+		t.Errorf("expected level warn, got %q", Level())
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("expected error for an unknown level, got none")
+	}
+	if Level() != "warn" {
+		t.Errorf("expected the level unchanged, got %q", Level())
+	}
+}
@@ -1,73 +1,301 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/flmailla/resume/config"
 	"github.com/flmailla/resume/db"
+	"github.com/flmailla/resume/docs"
 	"github.com/flmailla/resume/handlers"
 	"github.com/flmailla/resume/internal/auth"
+	"github.com/flmailla/resume/internal/metrics"
+	"github.com/flmailla/resume/internal/middleware"
+	"github.com/flmailla/resume/internal/ratelimit"
+	"github.com/flmailla/resume/internal/tracing"
 	"github.com/flmailla/resume/logger"
 )
 
 // @title Resume API
 // @version 1.0.0
 // @description Displays my resume main sections as APIs
-// @host localhost:8080
-// @BasePath /resume/v1
+// @BasePath /
 
 // @contact.name flmailla
 // @contact.email florent@maillard.icu
 
 // @securityDefinitions.oauth2.application OAuth2Application
-// @tokenUrl https://example.com/oauth/token
+// @tokenUrl https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/oauth2/v2.0/token
 // @scope.write Grants write access
 // @scope.admin Grants read and write access to administrative information
 
+// @securityDefinitions.apikey BearerToken
+// @in header
+// @name Authorization
+// @description A token or an API key, sent as "Bearer <token>"
+
+// @securityDefinitions.apikey APIKey
+// @in header
+// @name X-API-Key
+
 func main() {
 
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	cfg, options, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if options.PrintConfig {
+		fmt.Print(cfg)
+		return
+	}
 
-	go func() {
-		<-sigs
-		logger.Logger.Error("Received signal, shutting down...")
-		os.Exit(0)
-	}()
+	if len(args) > 0 {
+		os.Exit(runCommand(cfg, args))
+	}
 
-	if err := logger.InitLogger(); err != nil {
+	if err := logger.InitLogger(logger.Options{
+		Level:      cfg.Log.Level,
+		Format:     cfg.Log.Format,
+		Stdout:     cfg.Log.Stdout,
+		File:       cfg.Log.File,
+		MaxSizeMB:  cfg.Log.MaxSizeMB,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAgeDays: cfg.Log.MaxAgeDays,
+		Compress:   cfg.Log.Compress,
+	}); err != nil {
 		panic("Failed to initialize logger")
 	}
+	stopReload := reloadOnHangup(os.Args[1:])
 
-	if err := db.InitDB(); err != nil {
-		logger.Logger.Error("Failed to initialize database", "error", err)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint, cfg.Tracing.ServiceName)
+	if err != nil {
+		logger.Logger.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
 	}
-	defer db.CloseDB()
+
+	var store db.Storage
+	if cfg.Database.Storage == "memory" {
+		logger.Logger.Warn("Using the memory storage, changes are lost on exit")
+		store = db.NewMemoryStore()
+	} else {
+		if err := db.InitDB(cfg.Database.DSN, sqliteOptions(cfg.Database.SQLite)); err != nil {
+			logger.Logger.Error("Failed to initialize database", "error", err)
+			os.Exit(1)
+		}
+		store = db.NewStoreFromPools(db.DB, db.ReadDB, cfg.Database.QueryTimeout)
+	}
+
+	cacheConfig := cfg.Server.Cache
+	responses := middleware.NewResponseCache(cacheConfig.Entries, cacheConfig.TTL, cacheConfig.MaxAge)
+	store = db.NotifyChanges(store, responses.Invalidate)
 
 	logger.Logger.Info("Application started")
 
-	store := db.NewStoreFromSQLDB(db.DB)
 	profileHandler := handlers.NewProfileHandler(store)
 	experienceHandler := handlers.NewExperienceHandler(store)
 	skillHandler := handlers.NewSkillHandler(store)
 	educationHandler := handlers.NewEducationHandler(store)
 	licenceHandler := handlers.NewLicenceHandler(store)
-	healthHandler := handlers.NewHealthHandler(store)
+
+	var snapshot func(ctx context.Context) (io.ReadCloser, error)
+	stopBackups := func() {}
+	if cfg.Database.Storage == "sql" && db.DialectOf(cfg.Database.DSN) == db.SQLite {
+		snapshot = func(ctx context.Context) (io.ReadCloser, error) {
+			return db.Snapshot(ctx, backupSource())
+		}
+		if backup := cfg.Database.Backup; backup.Dir != "" {
+			stopBackups = scheduleBackups(backupSource(), backup.Dir, backup.Interval, backup.Keep)
+		}
+	} else if cfg.Database.Backup.Dir != "" {
+		logger.Logger.Warn("Scheduled backups need a SQLite database, none will be made")
+	}
+
+	checks := []handlers.Checker{
+		handlers.NewCheck("database", store.Ping),
+		handlers.NewCheck("schema", store.CheckSchemaVersion),
+	}
+	// Tokens of the identity provider, only checked when accepted
+	var validator *auth.JWTValidator
+	if cfg.Auth.JWKSURL != "" {
+		validator = auth.NewIssuerJWTValidator(cfg.Auth.JWKSURL, cfg.Auth.Issuer, cfg.Auth.Audience)
+		checks = append(checks, handlers.NewCheck("jwks", validator.CheckKeys))
+	}
+	adminHandler := handlers.NewAdminHandler(snapshot)
+	healthHandler := handlers.NewHealthHandler(checks...)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /profiles/{profile_id}", profileHandler.GetProfile)
-	mux.HandleFunc("GET /profiles/{profile_id}/experiences", experienceHandler.GetExperiencesByProfile)
-	mux.HandleFunc("GET /profiles/{profile_id}/skills", skillHandler.GetSkillsByProfile)
-	mux.HandleFunc("GET /profiles/{profile_id}/educations", educationHandler.GetEducationsByProfile)
-	mux.HandleFunc("GET /profiles/{profile_id}/licences", licenceHandler.GetLicencesByProfile)
-	mux.HandleFunc("GET /experiences/{experience_id}/skills", skillHandler.GetSkillsByExperience)
-	mux.HandleFunc("GET /skills", skillHandler.GetSkills)
+	// Resume routes, served through the response cache
+	cached := func(handler http.HandlerFunc) http.Handler {
+		return responses.Handler(handler)
+	}
+	mux.Handle("GET /profiles/{profile_id}", cached(profileHandler.GetProfile))
+	mux.Handle("GET /profiles/{profile_id}/experiences", cached(experienceHandler.GetExperiencesByProfile))
+	mux.Handle("GET /profiles/{profile_id}/skills", cached(skillHandler.GetSkillsByProfile))
+	mux.Handle("GET /profiles/{profile_id}/educations", cached(educationHandler.GetEducationsByProfile))
+	mux.Handle("GET /profiles/{profile_id}/licences", cached(licenceHandler.GetLicencesByProfile))
+	mux.Handle("GET /experiences/{experience_id}/skills", cached(skillHandler.GetSkillsByExperience))
+	mux.Handle("GET /skills", cached(skillHandler.GetSkills))
 	mux.HandleFunc("GET /health", healthHandler.GetHealthStatus)
+	mux.HandleFunc("GET /health/live", healthHandler.GetLiveness)
+	mux.HandleFunc("GET /health/ready", healthHandler.GetReadiness)
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+	mux.HandleFunc("GET /admin/log-level", adminHandler.GetLogLevel)
+	mux.HandleFunc("PUT /admin/log-level", adminHandler.SetLogLevel)
+	mux.HandleFunc("GET /admin/backup", adminHandler.GetBackup)
+
+	authenticators := auth.Chain{}
+	if len(cfg.Server.TLS.ClientIdentities) > 0 {
+		identities, err := auth.ParseCertIdentities(cfg.Server.TLS.CertIdentitiesSpec())
+		if err != nil {
+			logger.Logger.Error("Failed to parse the client certificate identities", "error", err)
+			os.Exit(1)
+		}
+		authenticators = append(authenticators, auth.NewCertAuthenticator(identities))
+	}
+	authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(store))
+	if cfg.Auth.HMACSecret != "" {
+		authenticators = append(authenticators, auth.NewHMACAuthenticator([]byte(cfg.Auth.HMACSecret)))
+	}
+	if cfg.Auth.OAuth.Issuer != "" {
+		key, err := auth.LoadSigningKey(cfg.Auth.OAuth.KeyFile)
+		if err != nil {
+			logger.Logger.Error("Failed to load the token signing key", "error", err)
+			os.Exit(1)
+		}
+		issuer := auth.NewTokenIssuer(key, cfg.Auth.OAuth.Issuer, store)
+		mux.HandleFunc("POST /oauth/token", issuer.TokenHandler)
+		mux.HandleFunc("GET /.well-known/jwks.json", issuer.JWKSHandler)
+		authenticators = append(authenticators, issuer.Validator())
+	}
+	if introspection := cfg.Auth.Introspection; introspection.Endpoint != "" {
+		authenticators = append(authenticators, auth.NewIntrospectionValidator(
+			introspection.Endpoint,
+			introspection.ClientID,
+			introspection.ClientSecret,
+			introspection.Issuer))
+	}
+	if validator != nil {
+		authenticators = append(authenticators, validator)
+	}
 
-	validator := auth.NewJWTValidator("https://login.microsoftonline.com/df111d67-4cb1-4119-9f05-4c52e5e0e150/discovery/v2.0/keys")
-	wrapped := validator.AuthMiddleware(mux)
+	publicRoutes := cfg.Server.PublicRoutes
+	if cfg.Server.Docs {
+		// Tokens are minted by the embedded issuer when enabled
+		var tokenURL string
+		if cfg.Auth.OAuth.Issuer != "" {
+			tokenURL = strings.TrimSuffix(cfg.Auth.OAuth.Issuer, "/") + "/oauth/token"
+		}
+		docsHandler, err := handlers.NewDocsHandler(docs.SwaggerInfo.ReadDoc(), docs.UI, tokenURL, cfg.Server.PublicRoutes)
+		if err != nil {
+			logger.Logger.Error("Failed to load the API documentation", "error", err)
+			os.Exit(1)
+		}
+		mux.HandleFunc("GET /openapi.json", docsHandler.GetSpec)
+		mux.HandleFunc("GET /docs", docsHandler.GetUI)
+		mux.HandleFunc("GET /docs/", docsHandler.GetUI)
+		publicRoutes = append(slices.Clone(publicRoutes), "GET /openapi.json", "GET /docs", "GET /docs/")
+	}
+
+	var routeLimits []middleware.RouteLimit
+	for _, route := range cfg.Server.Routes {
+		routeLimits = append(routeLimits, middleware.RouteLimit(route))
+	}
+	rateLimit := cfg.Server.RateLimit
+	var routeRateLimits []middleware.RouteRateLimit
+	for _, route := range rateLimit.Routes {
+		routeRateLimits = append(routeRateLimits, middleware.RouteRateLimit(route))
+	}
+	// Checked when the config was validated
+	proxies, _ := ratelimit.ParseProxies(rateLimit.TrustedProxies)
+	var authenticator auth.Authenticator = authenticators
+	if rateLimit.FailedAuthRequests > 0 {
+		authenticator = auth.LimitFailures(authenticators, ratelimit.New(rateLimit.FailedAuthRequests, rateLimit.FailedAuthPeriod), proxies)
+	}
+
+	wrapped := middleware.Stack(mux,
+		middleware.RequestID,
+		middleware.Tracing(mux),
+		middleware.AccessLog(mux),
+		middleware.Metrics(mux),
+		middleware.CORS(middleware.CORSPolicy(cfg.Server.CORS)),
+		middleware.Compress(cfg.Server.Compression.Encodings, cfg.Server.Compression.MinSize, cfg.Server.Compression.ContentTypes),
+		middleware.Recover,
+		middleware.Limits(cfg.Server.MaxBodyBytes, routeLimits),
+		func(next http.Handler) http.Handler {
+			return auth.Chain{authenticator}.AuthMiddleware(next, publicRoutes...)
+		},
+		middleware.RateLimit(rateLimit.Requests, rateLimit.Period, routeRateLimits, proxies),
+	)
 
-	http.ListenAndServe("localhost:8090", wrapped)
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           wrapped,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	if cfg.Server.TLS.CertFile != "" {
+		tlsConfig, err := auth.NewServerTLSConfig(cfg.Server.TLS.ClientCAFile)
+		if err != nil {
+			logger.Logger.Error("Failed to configure TLS", "error", err)
+			os.Exit(1)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		logger.Logger.Error("Failed to listen", "error", err)
+		os.Exit(1)
+	}
+
+	exitCode := 0
+	if err := serve(server, listener, healthHandler, cfg.Server); err != nil {
+		logger.Logger.Error("Server stopped", "error", err)
+		exitCode = 1
+	}
+
+	// Close in the reverse order of initialization, the logs last
+	stopReload()
+	stopBackups()
+	if err := store.Close(); err != nil {
+		logger.Logger.Error("Failed to close the prepared statements", "error", err)
+		exitCode = 1
+	}
+	if err := db.CloseDB(); err != nil {
+		logger.Logger.Error("Failed to close the database", "error", err)
+		exitCode = 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Logger.Error("Failed to flush the spans", "error", err)
+	}
+	cancel()
+	logger.Logger.Info("Application stopped")
+	logger.CloseLogger()
+
+	os.Exit(exitCode)
+}
+
+func sqliteOptions(c config.SQLiteConfig) db.SQLiteOptions {
+	return db.SQLiteOptions{
+		JournalMode:    c.JournalMode,
+		Synchronous:    c.Synchronous,
+		BusyTimeout:    c.BusyTimeout,
+		ForeignKeys:    c.ForeignKeys,
+		ReadConns:      c.ReadConnections,
+		IntegrityCheck: c.IntegrityCheck,
+	}
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Static credential used by batch jobs
+// Only the hash of the key is stored
+type APIKey struct {
+	ID         int64
+	Name       string
+	Hash       string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
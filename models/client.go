@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuth2 client allowed to use the client credentials grant
+// Only the hash of the secret is stored
+type OAuthClient struct {
+	ID         int64
+	ClientID   string
+	Name       string
+	SecretHash string
+	Scopes     []string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
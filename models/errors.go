@@ -18,7 +18,20 @@ var (
 	ErrScanFailed            = errors.New("db scan failed")
 	ErrNoTokenSent           = errors.New("no token sent")
 	ErrNotBearer             = errors.New("not a bearer token")
+	ErrNoClientCert          = errors.New("no client certificate")
 	ErrUnauthorized          = errors.New("unauthorized")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrAPIKeyRevoked         = errors.New("api key revoked")
+	ErrClientNotFound        = errors.New("oauth client not found")
+	ErrBodyTooLarge          = errors.New("request body too large")
+	ErrSchemaVersion         = errors.New("unexpected schema version")
+	ErrJWKSNotWarm           = errors.New("no JWKS key cached")
+	ErrForbidden             = errors.New("forbidden")
+	ErrInvalidBody           = errors.New("invalid request body")
+	ErrBackupUnsupported     = errors.New("backups need a SQLite database")
+	ErrDatabaseInUse         = errors.New("database in use, stop the server first")
+	ErrAuthUnavailable       = errors.New("authentication unavailable")
+	ErrTooManyRequests       = errors.New("too many requests")
 )
 
 // ErrorResponse represents an error response
@@ -5,15 +5,16 @@ import (
 )
 
 // Basically, me
+// Personal data is hidden from anonymous callers
 type Profile struct {
 	ID         int64
 	FirstName  string
 	LastName   string
-	BirthDate  time.Time
+	BirthDate  time.Time `redact:"anonymous"`
 	Pronoun    string
-	Email      string
+	Email      string `redact:"anonymous"`
 	Location   string
-	PostalCode int32
+	PostalCode int32 `redact:"anonymous"`
 	Headline   string
 	About      string
 }
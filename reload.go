@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/flmailla/resume/config"
+	"github.com/flmailla/resume/logger"
+)
+
+// Reload the config on SIGHUP and apply its log level, the other
+// settings requiring a restart. The returned function stops watching.
+func reloadOnHangup(args []string) func() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigs:
+			}
+
+			cfg, _, _, err := config.Load(args)
+			if err != nil {
+				logger.Logger.Error("Failed to reload the config, log level unchanged", "error", err)
+				continue
+			}
+			previous := logger.Level()
+			if err := logger.SetLevel(cfg.Log.Level); err != nil {
+				logger.Logger.Error("Failed to change the log level", "error", err)
+				continue
+			}
+			logger.Logger.Info("Config reloaded", "log_level", logger.Level(), "previous_log_level", previous)
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/logger"
+)
+
+func TestReloadOnHangup(t *testing.T) {
+	logger.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	logger.SetLevel("info")
+	t.Cleanup(func() { logger.SetLevel("info") })
+
+	path := t.TempDir() + "/resume.yaml"
+	writeFile(t, path, "log:\n  level: info\n")
+
+	stop := reloadOnHangup([]string{"--config", path})
+	defer stop()
+
+	writeFile(t, path, "log:\n  level: debug\n")
+	waitForLevel(t, "debug")
+
+	// An invalid config keeps the current level
+	writeFile(t, path, "log:\n  level: verbose\n")
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	time.Sleep(100 * time.Millisecond)
+	if logger.Level() != "debug" {
+		t.Errorf("expected level debug to be kept, got %q", logger.Level())
+	}
+
+	writeFile(t, path, "log:\n  level: warn\n")
+	waitForLevel(t, "warn")
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+// Send SIGHUP and wait for the level to be applied, the signal being handled asynchronously
+func waitForLevel(t *testing.T, want string) {
+	t.Helper()
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected level %q after SIGHUP, got %q", want, logger.Level())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
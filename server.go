@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/flmailla/resume/config"
+	"github.com/flmailla/resume/handlers"
+	"github.com/flmailla/resume/logger"
+)
+
+// Serve until SIGTERM or SIGINT is received, then report the service as
+// not ready, stop accepting connections and drain the in-flight requests
+func serve(server *http.Server, listener net.Listener, health *handlers.HealthHandler, cfg config.ServerConfig) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigs)
+
+	errs := make(chan error, 1)
+	go func() {
+		if cfg.TLS.CertFile == "" {
+			errs <- server.Serve(listener)
+		} else {
+			errs <- server.ServeTLS(listener, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-sigs:
+		logger.Logger.Info("Received signal, shutting down", "signal", sig.String())
+	}
+
+	health.SetDraining()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/flmailla/resume/config"
+	"github.com/flmailla/resume/handlers"
+	"github.com/flmailla/resume/logger"
+)
+
+// Start serve with a handler blocking until release is closed
+func startTestServer(t *testing.T, cfg config.ServerConfig, release chan struct{}) (string, chan struct{}, chan error) {
+	t.Helper()
+	logger.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	health := handlers.NewHealthHandler()
+	started := make(chan struct{}, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", health.GetHealthStatus)
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(&http.Server{Handler: mux}, listener, health, cfg)
+	}()
+
+	return "http://" + listener.Addr().String(), started, done
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	release := make(chan struct{})
+	url, started, done := startTestServer(t, config.ServerConfig{
+		ShutdownDelay:   200 * time.Millisecond,
+		ShutdownTimeout: 5 * time.Second,
+	}, release)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		inFlight <- result{status: resp.StatusCode, body: string(body)}
+	}()
+
+	// Wait for the request to reach the handler before signaling
+	<-started
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := http.Get(url + "/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness to flip to %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	close(release)
+
+	got := <-inFlight
+	if got.err != nil || got.status != http.StatusOK || got.body != "done" {
+		t.Errorf("expected the in-flight request to complete, got %+v", got)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+
+	if _, err := http.Get(url + "/health"); err == nil {
+		t.Error("expected new connections to be refused after shutdown")
+	}
+}
+
+func TestShutdownDeadlineExceeded(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	url, started, done := startTestServer(t, config.ServerConfig{
+		ShutdownTimeout: 100 * time.Millisecond,
+	}, release)
+
+	go http.Get(url + "/slow")
+
+	<-started
+	syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error when the drain deadline is exceeded, got none")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}